@@ -1,44 +1,114 @@
 from __future__ import annotations
 
+import logging
 import os
 from sqlalchemy import create_engine, event, text
 from sqlalchemy.orm import sessionmaker
+from sqlalchemy.pool import StaticPool
 
 from app.config import settings
 
+logger = logging.getLogger(__name__)
 
-def _ensure_sqlite_dir(url: str) -> None:
-    if not url.startswith("sqlite"):
-        return
-    # sqlite:///./data/listen.db -> ./data/listen.db
+
+def _is_memory_url(url: str) -> bool:
+    return url.startswith("sqlite") and (
+        ":memory:" in url or "mode=memory" in url or url.rstrip("/") == "sqlite:"
+    )
+
+
+def sqlite_file_path(url: str) -> str | None:
+    """
+    Extract the on-disk file path from a SQLite URL, tolerating query
+    parameters (?cache=shared&mode=...) and file: URIs. Returns None for
+    non-SQLite or in-memory URLs.
+    """
+    if not url.startswith("sqlite") or _is_memory_url(url):
+        return None
     path = url.split("sqlite:///")[-1]
+    # Strip query parameters; they belong to the driver, not the filesystem.
+    path = path.split("?", 1)[0]
+    if path.startswith("file:"):
+        path = path[len("file:"):]
     if path.startswith("./"):
         path = path[2:]
+    return path or None
+
+
+def _ensure_sqlite_dir(url: str) -> None:
+    path = sqlite_file_path(url)
+    if not path:
+        return
     dir_path = os.path.dirname(path)
     if dir_path:
         os.makedirs(dir_path, exist_ok=True)
 
 
+def _engine_kwargs(url: str) -> dict:
+    if not url.startswith("sqlite"):
+        return {"pool_pre_ping": True}
+    kwargs: dict = {
+        "connect_args": {"check_same_thread": False},
+        "pool_pre_ping": True,
+    }
+    if _is_memory_url(url):
+        # Each pooled connection normally gets its own private in-memory DB,
+        # which would "lose" the schema between requests. Pin everything to a
+        # single shared connection instead (tests / ephemeral mode).
+        kwargs["poolclass"] = StaticPool
+    return kwargs
+
+
 _ensure_sqlite_dir(settings.database_url)
 
-engine = create_engine(
-    settings.database_url,
-    connect_args={"check_same_thread": False} if settings.database_url.startswith("sqlite") else {},
-    pool_pre_ping=True,
-)
+engine = create_engine(settings.database_url, **_engine_kwargs(settings.database_url))
 
 
-@event.listens_for(engine, "connect")
-def _on_connect(dbapi_connection, _connection_record):
-    # SQLite pragmas for better concurrency and FK integrity
+def _apply_sqlite_pragmas(dbapi_connection, writable: bool) -> None:
+    """
+    Per-connection SQLite setup. The pool opens new connections at any time,
+    and most pragmas are connection-scoped, so everything must run here — a
+    one-time exec at startup would silently not apply to later connections.
+    """
     cursor = dbapi_connection.cursor()
     cursor.execute("PRAGMA foreign_keys=ON;")
-    cursor.execute("PRAGMA journal_mode=WAL;")
-    cursor.execute("PRAGMA synchronous=NORMAL;")
+    # Wait for a competing writer instead of failing with "database is
+    # locked" immediately.
+    cursor.execute(f"PRAGMA busy_timeout={int(settings.sqlite_busy_timeout_ms)};")
+    if writable:
+        # Journal settings need write access (a mode=ro connection can't
+        # switch journal modes).
+        cursor.execute("PRAGMA journal_mode=WAL;")
+        cursor.execute("PRAGMA synchronous=NORMAL;")
+    # Optional page-cache / mmap tuning for large DBs (see config notes on
+    # per-connection memory cost). 0 keeps SQLite's defaults.
+    if settings.sqlite_cache_size:
+        cursor.execute(f"PRAGMA cache_size={int(settings.sqlite_cache_size)};")
+    if settings.sqlite_mmap_size:
+        cursor.execute(f"PRAGMA mmap_size={int(settings.sqlite_mmap_size)};")
     cursor.close()
 
 
+@event.listens_for(engine, "connect")
+def _on_connect(dbapi_connection, _connection_record):
+    _apply_sqlite_pragmas(dbapi_connection, writable=True)
+
+
+# Optional dedicated pool for read-heavy queries so listing/search doesn't
+# contend with job writes. Defaults to the primary engine when unset.
+if settings.database_read_url:
+    read_engine = create_engine(settings.database_read_url, **_engine_kwargs(settings.database_read_url))
+
+    @event.listens_for(read_engine, "connect")
+    def _on_read_connect(dbapi_connection, _connection_record):
+        _apply_sqlite_pragmas(dbapi_connection, writable=False)
+
+else:
+    read_engine = engine
+
+
 SessionLocal = sessionmaker(autocommit=False, autoflush=False, bind=engine)
+ReadSessionLocal = sessionmaker(autocommit=False, autoflush=False, bind=read_engine)
 
 
 def ensure_schema() -> None:
@@ -60,6 +130,119 @@ def ensure_schema() -> None:
         cols = [row[1] for row in conn.execute(text("PRAGMA table_info(uploads)")).fetchall()]
         if "tags" not in cols:
             conn.execute(text("ALTER TABLE uploads ADD COLUMN tags TEXT"))
+        if "profile_id" not in cols:
+            conn.execute(text("ALTER TABLE uploads ADD COLUMN profile_id INTEGER REFERENCES profiles(id) ON DELETE SET NULL"))
+        if "is_favorite" not in cols:
+            conn.execute(text("ALTER TABLE uploads ADD COLUMN is_favorite BOOLEAN NOT NULL DEFAULT 0"))
+        if "keywords" not in cols:
+            conn.execute(text("ALTER TABLE uploads ADD COLUMN keywords JSON"))
+        if "notes" not in cols:
+            conn.execute(text("ALTER TABLE uploads ADD COLUMN notes TEXT"))
+        if "content_hash" not in cols:
+            conn.execute(text("ALTER TABLE uploads ADD COLUMN content_hash TEXT"))
+            conn.execute(text("CREATE INDEX IF NOT EXISTS ix_uploads_content_hash ON uploads (content_hash)"))
+        if "audio_deleted_at" not in cols:
+            conn.execute(text("ALTER TABLE uploads ADD COLUMN audio_deleted_at DATETIME"))
+        if "archive_audio_key" not in cols:
+            conn.execute(text("ALTER TABLE uploads ADD COLUMN archive_audio_key TEXT"))
+            conn.execute(text("ALTER TABLE uploads ADD COLUMN archive_transcript_key TEXT"))
+            conn.execute(text("ALTER TABLE uploads ADD COLUMN archived_at DATETIME"))
+        if "trim_start_seconds" not in cols:
+            conn.execute(text("ALTER TABLE uploads ADD COLUMN trim_start_seconds FLOAT"))
+            conn.execute(text("ALTER TABLE uploads ADD COLUMN trim_end_seconds FLOAT"))
+
+        prof_cols = [row[1] for row in conn.execute(text("PRAGMA table_info(profiles)")).fetchall()]
+        if "default_language" not in prof_cols:
+            conn.execute(text("ALTER TABLE profiles ADD COLUMN default_language TEXT"))
+            conn.execute(text("ALTER TABLE profiles ADD COLUMN default_summarize BOOLEAN"))
+            conn.execute(text("ALTER TABLE profiles ADD COLUMN default_action_items BOOLEAN"))
+            conn.execute(text("ALTER TABLE profiles ADD COLUMN default_chunk_seconds INTEGER"))
+
+        tr_cols = [row[1] for row in conn.execute(text("PRAGMA table_info(transcripts)")).fetchall()]
+        if "status" not in tr_cols:
+            conn.execute(text("ALTER TABLE transcripts ADD COLUMN status TEXT NOT NULL DEFAULT 'complete'"))
+
+        job_cols = [row[1] for row in conn.execute(text("PRAGMA table_info(jobs)")).fetchall()]
+        if "celery_task_id" not in job_cols:
+            conn.execute(text("ALTER TABLE jobs ADD COLUMN celery_task_id TEXT"))
+        if "chunk_seconds" not in job_cols:
+            conn.execute(text("ALTER TABLE jobs ADD COLUMN chunk_seconds INTEGER"))
+        if "kind" not in job_cols:
+            conn.execute(text("ALTER TABLE jobs ADD COLUMN kind TEXT NOT NULL DEFAULT 'transcribe'"))
+        if "trim_silence" not in job_cols:
+            conn.execute(text("ALTER TABLE jobs ADD COLUMN trim_silence BOOLEAN NOT NULL DEFAULT 0"))
+        if "language" not in job_cols:
+            conn.execute(text("ALTER TABLE jobs ADD COLUMN language TEXT"))
+        if "priority" not in job_cols:
+            conn.execute(text("ALTER TABLE jobs ADD COLUMN priority INTEGER NOT NULL DEFAULT 5"))
+        if "chunk_state" not in job_cols:
+            conn.execute(text("ALTER TABLE jobs ADD COLUMN chunk_state JSON"))
+
+        seg_cols = [row[1] for row in conn.execute(text("PRAGMA table_info(transcript_segments)")).fetchall()]
+        if "kind" not in seg_cols:
+            conn.execute(text("ALTER TABLE transcript_segments ADD COLUMN kind TEXT NOT NULL DEFAULT 'speech'"))
+        if "confidence" not in seg_cols:
+            conn.execute(text("ALTER TABLE transcript_segments ADD COLUMN confidence FLOAT"))
+
+        # Composite indexes for the hot list/lookup queries; create_all only
+        # covers single-column indexes declared on the models.
+        conn.execute(
+            text(
+                "CREATE INDEX IF NOT EXISTS ix_uploads_profile_created "
+                "ON uploads (profile_id, created_at DESC)"
+            )
+        )
+        conn.execute(
+            text(
+                "CREATE INDEX IF NOT EXISTS ix_segments_upload_start "
+                "ON transcript_segments (upload_id, start_time)"
+            )
+        )
+
+    # Full-text index over transcript text. External-content FTS5 table kept
+    # in sync by triggers; populated with a rebuild the first time it's
+    # created so pre-existing rows are indexed. Guarded separately because
+    # SQLite can be built without FTS5 — search falls back to LIKE then.
+    try:
+        with engine.begin() as conn:
+            existed = (
+                conn.execute(
+                    text("SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'transcripts_fts'")
+                ).fetchone()
+                is not None
+            )
+            conn.execute(
+                text(
+                    "CREATE VIRTUAL TABLE IF NOT EXISTS transcripts_fts USING fts5("
+                    "text, content='transcripts', content_rowid='id')"
+                )
+            )
+            conn.execute(
+                text(
+                    "CREATE TRIGGER IF NOT EXISTS transcripts_fts_ai AFTER INSERT ON transcripts BEGIN "
+                    "INSERT INTO transcripts_fts(rowid, text) VALUES (new.id, new.text); "
+                    "END"
+                )
+            )
+            conn.execute(
+                text(
+                    "CREATE TRIGGER IF NOT EXISTS transcripts_fts_ad AFTER DELETE ON transcripts BEGIN "
+                    "INSERT INTO transcripts_fts(transcripts_fts, rowid, text) VALUES ('delete', old.id, old.text); "
+                    "END"
+                )
+            )
+            conn.execute(
+                text(
+                    "CREATE TRIGGER IF NOT EXISTS transcripts_fts_au AFTER UPDATE OF text ON transcripts BEGIN "
+                    "INSERT INTO transcripts_fts(transcripts_fts, rowid, text) VALUES ('delete', old.id, old.text); "
+                    "INSERT INTO transcripts_fts(rowid, text) VALUES (new.id, new.text); "
+                    "END"
+                )
+            )
+            if not existed:
+                conn.execute(text("INSERT INTO transcripts_fts(transcripts_fts) VALUES ('rebuild')"))
+    except Exception:
+        logger.warning("FTS5 unavailable; transcript search falls back to LIKE matching")
 
 
 def get_db():
@@ -70,6 +253,15 @@ def get_db():
         db.close()
 
 
+def get_read_db():
+    """Dependency for read-only queries; falls back to the primary pool."""
+    db = ReadSessionLocal()
+    try:
+        yield db
+    finally:
+        db.close()
+
+
 def db_healthcheck() -> bool:
     try:
         with engine.connect() as conn:
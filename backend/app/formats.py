@@ -0,0 +1,35 @@
+from __future__ import annotations
+
+"""
+Single registry of supported audio input formats.
+
+Upload validation, MIME sniffing and the /api/config endpoint all read from
+this table, so adding a format is a one-line change here. Everything listed
+must be decodable by the ffmpeg build in the worker image.
+"""
+
+# extension (without dot, lowercase) -> canonical MIME type
+AUDIO_FORMATS: dict[str, str] = {
+    "wav": "audio/wav",
+    "mp3": "audio/mpeg",
+    "m4a": "audio/mp4",
+    "mp4": "audio/mp4",
+    "aac": "audio/aac",
+    "ogg": "audio/ogg",
+    "opus": "audio/ogg",
+    "flac": "audio/flac",
+    "webm": "audio/webm",
+    "mkv": "audio/webm",
+    "wma": "audio/x-ms-wma",
+}
+
+
+def extension_supported(filename: str) -> bool:
+    """True when the filename's extension is a registered audio format."""
+    ext = filename.rsplit(".", 1)[-1].lower() if "." in filename else ""
+    return ext in AUDIO_FORMATS
+
+
+def mime_for_extension(filename: str) -> str | None:
+    ext = filename.rsplit(".", 1)[-1].lower() if "." in filename else ""
+    return AUDIO_FORMATS.get(ext)
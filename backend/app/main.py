@@ -1,17 +1,30 @@
 from __future__ import annotations
 
+import asyncio
+import logging
 import os
+import subprocess
+import uuid
 from pathlib import Path
 
-from fastapi import FastAPI
+from fastapi import FastAPI, HTTPException
 from fastapi.middleware.cors import CORSMiddleware
+from fastapi.middleware.gzip import GZipMiddleware
+from fastapi.responses import JSONResponse
 
 from app.config import settings
-from app.db import db_healthcheck, engine, ensure_schema
+from app.db import db_healthcheck, engine, ensure_schema, sqlite_file_path
+from app.formats import AUDIO_FORMATS
 from app.models import Prompt
+from app.routes.activity import router as activity_router
+from app.routes.admin import router as admin_router
 from app.routes.jobs import router as jobs_router
+from app.routes.languages import router as languages_router
+from app.routes.profiles import ensure_default_profile
+from app.routes.profiles import router as profiles_router
 from app.routes.prompts import router as prompts_router
 from app.routes.uploads import router as uploads_router
+from app.serialization import CamelCaseJSONResponse
 
 
 def _load_default_prompts() -> None:
@@ -56,27 +69,228 @@ Be factual and do not invent details.
         db.commit()
 
 
+logger = logging.getLogger(__name__)
+
+APP_VERSION = "0.1.0"
+
+
+def _ffmpeg_version() -> str | None:
+    try:
+        p = subprocess.run(["ffmpeg", "-version"], stdout=subprocess.PIPE, stderr=subprocess.DEVNULL, text=True)
+    except OSError:
+        return None
+    if p.returncode != 0 or not p.stdout:
+        return None
+    return p.stdout.splitlines()[0].strip()
+
+
+def _faster_whisper_version() -> str | None:
+    try:
+        from importlib.metadata import version
+
+        return version("faster-whisper")
+    except Exception:
+        # Not installed in the API container; only the worker needs it.
+        return None
+
+
+def _version_info() -> dict:
+    return {
+        "app": APP_VERSION,
+        "ffmpeg": _ffmpeg_version(),
+        "faster_whisper": _faster_whisper_version(),
+        "whisper_model": settings.whisper_model,
+        "whisper_device": settings.whisper_device,
+    }
+
+
+def _startup_problems() -> list[str]:
+    """
+    Self-check of everything the app needs at runtime. Returns human-readable
+    problem descriptions; empty means healthy. STRICT_STARTUP turns any
+    problem into a fatal error, otherwise they're logged as warnings.
+    """
+    problems: list[str] = []
+
+    if not db_healthcheck():
+        problems.append("database is not reachable")
+    else:
+        db_path = sqlite_file_path(settings.database_url)
+        if db_path and not os.access(db_path, os.W_OK):
+            problems.append(f"database file is not writable: {db_path}")
+
+    try:
+        probe = Path(settings.upload_dir) / ".startup-check"
+        probe.write_text("")
+        probe.unlink()
+    except OSError as e:
+        problems.append(f"upload dir is not writable: {settings.upload_dir} ({e})")
+
+    if _ffmpeg_version() is None:
+        problems.append("ffmpeg binary not found")
+
+    model = settings.whisper_model or ""
+    if (os.sep in model or model.startswith(".")) and not os.path.exists(model):
+        problems.append(f"whisper model path not found: {model}")
+
+    return problems
+
+
+# Streaming endpoints that REQUEST_TIMEOUT_SECONDS must never cut off.
+# Listed explicitly so adding a streaming route is a conscious decision here.
+_TIMEOUT_EXEMPT_SUFFIXES = (
+    "/audio",
+    "/segments",
+    "/transcript/segments.csv",
+    "/live",
+)
+
+
+def _timeout_exempt(path: str) -> bool:
+    return path.endswith(_TIMEOUT_EXEMPT_SUFFIXES)
+
+
+def _ensure_default_profile() -> None:
+    from sqlalchemy.orm import Session
+
+    with Session(engine) as db:
+        ensure_default_profile(db)
+
+
 def create_app() -> FastAPI:
     os.makedirs(settings.upload_dir, exist_ok=True)
     ensure_schema()
+
+    problems = _startup_problems()
+    if problems:
+        if settings.strict_startup:
+            raise RuntimeError("startup self-check failed: " + "; ".join(problems))
+        for p in problems:
+            logger.warning("Startup self-check: %s", p)
     _load_default_prompts()
+    if settings.auto_create_default_profile:
+        _ensure_default_profile()
+
+    info = _version_info()
+    logger.info(
+        "Starting Listen %s (ffmpeg: %s, faster-whisper: %s, model: %s)",
+        info["app"],
+        info["ffmpeg"] or "not found",
+        info["faster_whisper"] or "not installed",
+        info["whisper_model"],
+    )
+
+    dev_mode = settings.env == "dev"
+    response_class = CamelCaseJSONResponse if settings.api_field_case == "camel" else JSONResponse
+    app = FastAPI(
+        title="Listen API",
+        version=APP_VERSION,
+        description=(
+            "Self-hosted audio transcription: upload audio, track jobs, and "
+            "read transcripts, summaries and action items. The document at "
+            "/openapi.json is generated from the handlers and schemas, so it "
+            "is always in sync and suitable for typed client generation."
+        ),
+        openapi_tags=[
+            {"name": "uploads", "description": "Conversations: audio files, transcripts, segments"},
+            {"name": "jobs", "description": "Transcription/LLM job status and progress"},
+            {"name": "profiles", "description": "Upload profiles and per-profile defaults"},
+            {"name": "prompts", "description": "LLM prompts for summaries and action items"},
+            {"name": "activity", "description": "Recent activity feed"},
+            {"name": "admin", "description": "Operational endpoints (gated by ADMIN_API_KEY)"},
+            {"name": "languages", "description": "Supported language codes"},
+        ],
+        default_response_class=response_class,
+        # Interactive docs are a dev convenience; the OpenAPI document itself
+        # stays available in production for client generation.
+        docs_url="/docs" if dev_mode else None,
+        redoc_url="/redoc" if dev_mode else None,
+    )
+
+    if settings.request_timeout_seconds > 0:
+
+        @app.middleware("http")
+        async def _request_timeout(request, call_next):
+            if _timeout_exempt(request.url.path):
+                return await call_next(request)
+            try:
+                return await asyncio.wait_for(
+                    call_next(request), timeout=settings.request_timeout_seconds
+                )
+            except asyncio.TimeoutError:
+                return JSONResponse(status_code=504, content={"detail": "Request timed out"})
+
+    if dev_mode:
+        # Keep browsers from caching API responses while iterating on the
+        # frontend; production relies on the normal caching behavior.
+        @app.middleware("http")
+        async def _no_store(request, call_next):
+            response = await call_next(request)
+            response.headers.setdefault("Cache-Control", "no-store")
+            return response
+
+    # Registered last so it wraps the other request middlewares: every
+    # response (including 504s and error bodies) carries the id.
+    @app.middleware("http")
+    async def _request_id(request, call_next):
+        rid = request.headers.get("X-Request-ID") or uuid.uuid4().hex
+        request.state.request_id = rid
+        try:
+            response = await call_next(request)
+        except Exception:
+            # Last-resort handler: tie the 500 the user sees to the log line
+            # via the request id instead of an opaque "something broke".
+            logger.exception("Unhandled error handling %s %s (request_id=%s)", request.method, request.url.path, rid)
+            response = response_class(
+                status_code=500,
+                content={"detail": "Internal server error", "request_id": rid},
+            )
+        response.headers["X-Request-ID"] = rid
+        return response
+
+    @app.exception_handler(HTTPException)
+    async def _http_exception_with_request_id(request, exc: HTTPException):
+        body = {"detail": exc.detail}
+        rid = getattr(request.state, "request_id", None)
+        if rid:
+            body["request_id"] = rid
+        return response_class(status_code=exc.status_code, content=body, headers=exc.headers)
 
-    app = FastAPI(title="Listen API", version="0.1.0")
     app.add_middleware(
         CORSMiddleware,
         allow_origins=["*"],
         allow_credentials=True,
         allow_methods=["*"],
         allow_headers=["*"],
+        max_age=settings.cors_max_age,
     )
+    app.add_middleware(GZipMiddleware, minimum_size=1024, compresslevel=settings.compress_level)
 
     @app.get("/healthz")
     def healthz():
         return {"ok": True, "db": db_healthcheck()}
 
+    @app.get("/api/version")
+    def version():
+        return _version_info()
+
+    @app.get("/api/config")
+    def config():
+        # Client-facing configuration; the format list comes straight from
+        # the central registry in app.formats.
+        return {
+            "audio_formats": AUDIO_FORMATS,
+            "default_chunk_seconds": settings.audio_chunk_seconds,
+            "sniff_uploads": settings.sniff_uploads,
+        }
+
     app.include_router(uploads_router, prefix="/api")
     app.include_router(jobs_router, prefix="/api")
     app.include_router(prompts_router, prefix="/api")
+    app.include_router(profiles_router, prefix="/api")
+    app.include_router(activity_router, prefix="/api")
+    app.include_router(admin_router, prefix="/api")
+    app.include_router(languages_router, prefix="/api")
 
     return app
 
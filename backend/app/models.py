@@ -20,15 +20,38 @@ class Base(DeclarativeBase):
     pass
 
 
+class Profile(Base):
+    __tablename__ = "profiles"
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    name: Mapped[str] = mapped_column(String, nullable=False)
+    is_active: Mapped[bool] = mapped_column(Boolean, default=True, nullable=False)
+
+    # Per-profile transcription defaults, applied when the upload form omits
+    # the corresponding field. NULL means "no default" (global behavior).
+    default_language: Mapped[Optional[str]] = mapped_column(String, nullable=True)
+    default_summarize: Mapped[Optional[bool]] = mapped_column(Boolean, nullable=True)
+    default_action_items: Mapped[Optional[bool]] = mapped_column(Boolean, nullable=True)
+    default_chunk_seconds: Mapped[Optional[int]] = mapped_column(Integer, nullable=True)
+
+    created_at: Mapped[datetime] = mapped_column(DateTime, default=datetime.utcnow, nullable=False)
+    updated_at: Mapped[datetime] = mapped_column(DateTime, default=datetime.utcnow, onupdate=datetime.utcnow, nullable=False)
+
+    uploads: Mapped[list["Upload"]] = relationship(back_populates="profile")
+
+
 class Upload(Base):
     __tablename__ = "uploads"
 
     id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    profile_id: Mapped[Optional[int]] = mapped_column(ForeignKey("profiles.id", ondelete="SET NULL"), nullable=True, index=True)
     original_filename: Mapped[str] = mapped_column(String, nullable=False)
     display_name: Mapped[str] = mapped_column(String, nullable=False)
     stored_path: Mapped[str] = mapped_column(String, nullable=False)
     content_type: Mapped[Optional[str]] = mapped_column(String, nullable=True)
     size_bytes: Mapped[Optional[int]] = mapped_column(Integer, nullable=True)
+    # SHA-256 of the raw uploaded bytes, used for duplicate detection
+    content_hash: Mapped[Optional[str]] = mapped_column(String, nullable=True, index=True)
 
     created_at: Mapped[datetime] = mapped_column(DateTime, default=datetime.utcnow, nullable=False)
     updated_at: Mapped[datetime] = mapped_column(DateTime, default=datetime.utcnow, onupdate=datetime.utcnow, nullable=False)
@@ -36,6 +59,11 @@ class Upload(Base):
     duration_seconds: Mapped[Optional[float]] = mapped_column(Float, nullable=True)
     language: Mapped[Optional[str]] = mapped_column(String, nullable=True)
 
+    # Offsets removed by silence trimming (original file left intact);
+    # lets segment timestamps be mapped back to the original audio.
+    trim_start_seconds: Mapped[Optional[float]] = mapped_column(Float, nullable=True)
+    trim_end_seconds: Mapped[Optional[float]] = mapped_column(Float, nullable=True)
+
     # Optional LLM results
     summary: Mapped[Optional[str]] = mapped_column(Text, nullable=True)
     action_items: Mapped[Optional[dict]] = mapped_column(JSON, nullable=True)
@@ -43,6 +71,24 @@ class Upload(Base):
     # Comma-separated tags (stored lowercase). Example: "meeting,finance,urgent"
     tags: Mapped[Optional[str]] = mapped_column(Text, nullable=True)
 
+    is_favorite: Mapped[bool] = mapped_column(Boolean, default=False, nullable=False)
+
+    # Free-form user notes (who attended, context, etc.)
+    notes: Mapped[Optional[str]] = mapped_column(Text, nullable=True)
+
+    # Extracted topic keywords, e.g. ["budget", "roadmap"]
+    keywords: Mapped[Optional[list]] = mapped_column(JSON, nullable=True)
+
+    # Set when the audio file was removed by the AUDIO_RETENTION_DAYS
+    # sweeper; transcript and metadata survive, audio requests return 410.
+    audio_deleted_at: Mapped[Optional[datetime]] = mapped_column(DateTime, nullable=True)
+
+    # Object keys in the archive bucket once pushed (see services.archive)
+    archive_audio_key: Mapped[Optional[str]] = mapped_column(String, nullable=True)
+    archive_transcript_key: Mapped[Optional[str]] = mapped_column(String, nullable=True)
+    archived_at: Mapped[Optional[datetime]] = mapped_column(DateTime, nullable=True)
+
+    profile: Mapped[Optional["Profile"]] = relationship(back_populates="uploads")
     jobs: Mapped[list["Job"]] = relationship(back_populates="upload", cascade="all, delete-orphan")
     transcript: Mapped[Optional["Transcript"]] = relationship(back_populates="upload", cascade="all, delete-orphan", uselist=False)
     segments: Mapped[list["TranscriptSegment"]] = relationship(back_populates="upload", cascade="all, delete-orphan")
@@ -54,12 +100,30 @@ class Job(Base):
     id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
     upload_id: Mapped[int] = mapped_column(ForeignKey("uploads.id", ondelete="CASCADE"), index=True)
 
-    status: Mapped[str] = mapped_column(String, default="queued", nullable=False)  # queued|processing|completed|failed
+    # What kind of work this job does; dispatched via worker.tasks.JOB_HANDLERS
+    kind: Mapped[str] = mapped_column(String, default="transcribe", nullable=False)  # transcribe|llm|retranscribe_range
+    status: Mapped[str] = mapped_column(String, default="queued", nullable=False)  # queued|processing|completed|completed_with_errors|failed|canceled
+    celery_task_id: Mapped[Optional[str]] = mapped_column(String, nullable=True)
     phase: Mapped[Optional[str]] = mapped_column(String, nullable=True)  # uploading|chunking|transcribing|summarizing|action_items
     progress: Mapped[int] = mapped_column(Integer, default=0, nullable=False)
 
     total_chunks: Mapped[Optional[int]] = mapped_column(Integer, nullable=True)
     current_chunk: Mapped[Optional[int]] = mapped_column(Integer, nullable=True)
+    # Per-job override of the global AUDIO_CHUNK_SECONDS
+    chunk_seconds: Mapped[Optional[int]] = mapped_column(Integer, nullable=True)
+    # Language the run was pinned to (NULL = auto-detect). Persisted so the
+    # detail view and retries can reproduce the exact run.
+    language: Mapped[Optional[str]] = mapped_column(String, nullable=True)
+    # Strip leading/trailing silence before transcription (opt-in)
+    trim_silence: Mapped[bool] = mapped_column(Boolean, default=False, nullable=False)
+    # Queue priority 0..10, higher runs first (RabbitMQ priority queue).
+    # Defaults by upload size: short clips get a bump over long recordings.
+    priority: Mapped[int] = mapped_column(Integer, default=5, nullable=False)
+
+    # 1-based indices of chunks already transcribed and committed. Chunking
+    # is deterministic for the same audio + chunk_seconds, so a retry can
+    # resume after the last good chunk instead of starting over.
+    chunk_state: Mapped[Optional[list]] = mapped_column(JSON, nullable=True)
 
     summarize: Mapped[bool] = mapped_column(Boolean, default=False, nullable=False)
     generate_action_items: Mapped[bool] = mapped_column(Boolean, default=False, nullable=False)
@@ -82,11 +146,23 @@ class Transcript(Base):
     id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
     upload_id: Mapped[int] = mapped_column(ForeignKey("uploads.id", ondelete="CASCADE"), unique=True, index=True)
     text: Mapped[str] = mapped_column(Text, nullable=False)
+    status: Mapped[str] = mapped_column(String, default="complete", nullable=False)  # processing|complete
     created_at: Mapped[datetime] = mapped_column(DateTime, default=datetime.utcnow, nullable=False)
 
     upload: Mapped["Upload"] = relationship(back_populates="transcript")
 
 
+class TranscriptVersion(Base):
+    __tablename__ = "transcript_versions"
+
+    id: Mapped[int] = mapped_column(Integer, primary_key=True, autoincrement=True)
+    upload_id: Mapped[int] = mapped_column(ForeignKey("uploads.id", ondelete="CASCADE"), index=True)
+    version: Mapped[int] = mapped_column(Integer, nullable=False)
+    text: Mapped[str] = mapped_column(Text, nullable=False)
+    source: Mapped[str] = mapped_column(String, nullable=False)  # whisper|edit|restore
+    created_at: Mapped[datetime] = mapped_column(DateTime, default=datetime.utcnow, nullable=False)
+
+
 class TranscriptSegment(Base):
     __tablename__ = "transcript_segments"
 
@@ -95,10 +171,26 @@ class TranscriptSegment(Base):
     start_time: Mapped[float] = mapped_column(Float, nullable=False)
     end_time: Mapped[float] = mapped_column(Float, nullable=False)
     text: Mapped[str] = mapped_column(Text, nullable=False)
+    # speech|non_speech: whisper's bracketed markers ([MUSIC], [APPLAUSE],
+    # [BLANK_AUDIO]) are flagged so the UI can render or filter them.
+    kind: Mapped[str] = mapped_column(String, default="speech", nullable=False)
+    # 0..1, derived from whisper's avg_logprob. NULL for imported segments
+    # and rows written before this column existed.
+    confidence: Mapped[Optional[float]] = mapped_column(Float, nullable=True)
 
     upload: Mapped["Upload"] = relationship(back_populates="segments")
 
 
+class Setting(Base):
+    """Small key-value store for runtime state shared by API and worker."""
+
+    __tablename__ = "settings"
+
+    key: Mapped[str] = mapped_column(String, primary_key=True)
+    value: Mapped[str] = mapped_column(String, nullable=False)
+    updated_at: Mapped[datetime] = mapped_column(DateTime, default=datetime.utcnow, onupdate=datetime.utcnow, nullable=False)
+
+
 class Prompt(Base):
     __tablename__ = "prompts"
 
@@ -0,0 +1,47 @@
+from __future__ import annotations
+
+from fastapi import APIRouter, Depends
+from sqlalchemy import func
+from sqlalchemy.orm import Session
+
+from app.db import get_read_db
+from app.models import Job, Upload
+from app.schemas import ActivityItem
+
+router = APIRouter(prefix="/activity", tags=["activity"])
+
+
+@router.get("", response_model=list[ActivityItem])
+def recent_activity(limit: int = 20, db: Session = Depends(get_read_db)) -> list[ActivityItem]:
+    """
+    The N most recently updated uploads with their latest job inlined.
+    One joined query, so the home screen doesn't need a job call per upload.
+    """
+    limit = max(1, min(limit, 100))
+
+    latest_job = (
+        db.query(Job.upload_id, func.max(Job.id).label("job_id"))
+        .group_by(Job.upload_id)
+        .subquery()
+    )
+    rows = (
+        db.query(Upload, Job)
+        .outerjoin(latest_job, latest_job.c.upload_id == Upload.id)
+        .outerjoin(Job, Job.id == latest_job.c.job_id)
+        .order_by(Upload.updated_at.desc())
+        .limit(limit)
+        .all()
+    )
+    return [
+        ActivityItem(
+            id=u.id,
+            display_name=u.display_name,
+            updated_at=u.updated_at,
+            duration_seconds=u.duration_seconds,
+            job_id=j.id if j else None,
+            job_status=j.status if j else None,
+            job_phase=j.phase if j else None,
+            job_progress=j.progress if j else None,
+        )
+        for u, j in rows
+    ]
@@ -0,0 +1,253 @@
+from __future__ import annotations
+
+import os
+import threading
+import uuid
+from datetime import datetime
+
+from fastapi import APIRouter, Depends, Header, HTTPException
+from sqlalchemy import text
+from sqlalchemy.orm import Session
+
+from app.config import settings
+from app.db import engine, get_db, sqlite_file_path
+from app.models import Job, Upload
+from app.services.archive import archive_enabled, archive_upload
+from app.services.runtime_settings import queue_paused, set_queue_paused
+
+router = APIRouter(prefix="/admin", tags=["admin"])
+
+
+def require_admin(x_api_key: str | None = Header(None)) -> None:
+    """
+    Admin gate. When ADMIN_API_KEY is unset (dev / single-user setups) the
+    endpoints are open; set it in any shared deployment.
+    """
+    if settings.admin_api_key and x_api_key != settings.admin_api_key:
+        raise HTTPException(status_code=403, detail="Invalid admin API key")
+
+
+# Window for the moving average of job run time. Recent jobs only, so the
+# number reflects the current model/hardware rather than all-time history.
+_AVG_RUN_WINDOW = 100
+
+
+@router.get("/queue", dependencies=[Depends(require_admin)])
+def queue_stats(db: Session = Depends(get_db)) -> dict:
+    # All numbers are derived from the job table rather than in-process
+    # counters, so they're consistent across api/worker processes and
+    # survive restarts without drifting.
+    counts = {
+        status: db.query(Job).filter(Job.status == status).count()
+        for status in ("queued", "processing", "completed", "completed_with_errors", "failed", "canceled")
+    }
+    total_enqueued = db.query(Job).count()
+
+    recent = (
+        db.query(Job.started_at, Job.finished_at)
+        .filter(
+            Job.status.in_(["completed", "completed_with_errors"]),
+            Job.started_at.isnot(None),
+            Job.finished_at.isnot(None),
+        )
+        .order_by(Job.finished_at.desc())
+        .limit(_AVG_RUN_WINDOW)
+        .all()
+    )
+    durations = [(finished - started).total_seconds() for started, finished in recent]
+    avg_run = round(sum(durations) / len(durations), 2) if durations else None
+
+    oldest_queued = (
+        db.query(Job.created_at)
+        .filter(Job.status == "queued")
+        .order_by(Job.created_at.asc())
+        .limit(1)
+        .scalar()
+    )
+    oldest_age = (datetime.utcnow() - oldest_queued).total_seconds() if oldest_queued else None
+
+    return {
+        "queued": counts["queued"],
+        "processing": counts["processing"],
+        "completed": counts["completed"],
+        "completed_with_errors": counts["completed_with_errors"],
+        "failed": counts["failed"],
+        "canceled": counts["canceled"],
+        "total_enqueued": total_enqueued,
+        "avg_run_seconds": avg_run,
+        "oldest_queued_age_seconds": oldest_age,
+        "paused": queue_paused(db),
+    }
+
+
+@router.post("/queue/pause", dependencies=[Depends(require_admin)])
+def pause_queue(db: Session = Depends(get_db)) -> dict:
+    """
+    Stop workers from picking up new jobs. In-flight jobs finish; queued
+    jobs accumulate and run again after resume.
+    """
+    set_queue_paused(db, True)
+    return {"paused": True}
+
+
+@router.post("/queue/resume", dependencies=[Depends(require_admin)])
+def resume_queue(db: Session = Depends(get_db)) -> dict:
+    set_queue_paused(db, False)
+    return {"paused": False}
+
+
+# Prevents two optimize runs from racing; VACUUM in particular rewrites the
+# whole file and must not overlap with itself.
+_OPTIMIZE_LOCK = threading.Lock()
+
+
+def _db_file_size() -> int | None:
+    path = sqlite_file_path(settings.database_url)
+    if not path:
+        return None
+    try:
+        return os.path.getsize(path)
+    except OSError:
+        return None
+
+
+@router.post("/optimize", dependencies=[Depends(require_admin)])
+def optimize_database(vacuum: bool = False) -> dict:
+    """
+    Run PRAGMA optimize + ANALYZE, and optionally VACUUM (?vacuum=true).
+    VACUUM rewrites the whole database file, so it's opt-in.
+    """
+    if not settings.database_url.startswith("sqlite"):
+        raise HTTPException(status_code=400, detail="Optimize is only supported on SQLite")
+    if not _OPTIMIZE_LOCK.acquire(blocking=False):
+        raise HTTPException(status_code=409, detail="An optimize run is already in progress")
+    try:
+        size_before = _db_file_size()
+        # All three statements run on an AUTOCOMMIT connection: VACUUM can't
+        # run inside a transaction at all, and optimize/ANALYZE results would
+        # be rolled back by the connection's implicit transaction otherwise.
+        with engine.connect().execution_options(isolation_level="AUTOCOMMIT") as conn:
+            conn.execute(text("PRAGMA optimize"))
+            conn.execute(text("ANALYZE"))
+            if vacuum:
+                conn.execute(text("VACUUM"))
+        size_after = _db_file_size()
+        return {
+            "vacuumed": vacuum,
+            "size_before_bytes": size_before,
+            "size_after_bytes": size_after,
+        }
+    finally:
+        _OPTIMIZE_LOCK.release()
+
+
+@router.get("/errors", dependencies=[Depends(require_admin)])
+def recent_errors(limit: int = 50, db: Session = Depends(get_db)) -> list[dict]:
+    """
+    Most recent jobs that ended with an error (failed or
+    completed_with_errors), newest first. Quicker than scraping logs, and
+    pairs with POST /admin/jobs/retry-failed.
+    """
+    if not (1 <= limit <= 500):
+        raise HTTPException(status_code=400, detail="limit must be between 1 and 500")
+    jobs = (
+        db.query(Job)
+        .filter(Job.status.in_(["failed", "completed_with_errors"]))
+        .order_by(Job.finished_at.desc(), Job.id.desc())
+        .limit(limit)
+        .all()
+    )
+    return [
+        {
+            "job_id": j.id,
+            "upload_id": j.upload_id,
+            "kind": j.kind,
+            "status": j.status,
+            "error": j.error,
+            "finished_at": j.finished_at,
+        }
+        for j in jobs
+    ]
+
+
+@router.post("/jobs/retry-failed", dependencies=[Depends(require_admin)])
+def retry_failed_jobs(limit: int = 0, db: Session = Depends(get_db)) -> dict:
+    """
+    Reset failed jobs back to queued and re-enqueue them (e.g. after fixing
+    a missing model). Skips jobs whose audio file is gone and kinds whose
+    task arguments aren't persisted on the job row (retranscribe_range).
+    Use ?limit=N to retry in batches instead of flooding the queue.
+    """
+    from worker.celery_app import celery_app
+
+    q = db.query(Job).filter(Job.status == "failed").order_by(Job.created_at.asc())
+    if limit > 0:
+        q = q.limit(limit)
+    failed = q.all()
+
+    retried = 0
+    skipped = 0
+    for job in failed:
+        if job.kind not in ("transcribe", "llm"):
+            skipped += 1
+            continue
+        upload = db.query(Upload).filter(Upload.id == job.upload_id).first()
+        if not upload or not upload.stored_path or not os.path.exists(upload.stored_path):
+            skipped += 1
+            continue
+        # Stamp the new task id before sending so any stale redelivery of
+        # the old message is dropped by the dispatcher instead of racing
+        # the retry.
+        task_id = uuid.uuid4().hex
+        job.status = "queued"
+        job.progress = 0
+        job.phase = None
+        job.error = None
+        job.current_chunk = None
+        job.started_at = None
+        job.finished_at = None
+        job.celery_task_id = task_id
+        db.commit()
+        celery_app.send_task("worker.tasks.process_job", args=[job.id], priority=job.priority, task_id=task_id)
+        retried += 1
+
+    return {"retried": retried, "skipped": skipped}
+
+
+@router.post("/uploads/{upload_id}/archive", dependencies=[Depends(require_admin)])
+def archive_upload_now(upload_id: int, db: Session = Depends(get_db)) -> dict:
+    """
+    Push an upload's audio + transcript to the configured S3-compatible
+    bucket immediately (see also ARCHIVE_ON_COMPLETION for the automatic path).
+    """
+    if not archive_enabled():
+        raise HTTPException(status_code=503, detail="Archival is not configured (ARCHIVE_S3_BUCKET unset)")
+    u = db.query(Upload).filter(Upload.id == upload_id).first()
+    if not u:
+        raise HTTPException(status_code=404, detail="Upload not found")
+    try:
+        return archive_upload(db, u)
+    except RuntimeError as exc:
+        raise HTTPException(status_code=503, detail=str(exc))
+    except Exception as exc:
+        raise HTTPException(status_code=502, detail=f"Archive upload failed: {exc}")
+
+
+@router.post("/reindex", dependencies=[Depends(require_admin)])
+def reindex_transcripts() -> dict:
+    """
+    Rebuild the transcripts_fts index from the transcripts table. The
+    triggers installed by ensure_schema keep it current in normal operation;
+    use this if it drifts after manual DB edits or a crash. SQLite only.
+    """
+    if not settings.database_url.startswith("sqlite"):
+        raise HTTPException(status_code=400, detail="Reindex is only supported on SQLite")
+
+    try:
+        with engine.begin() as conn:
+            conn.execute(text("INSERT INTO transcripts_fts(transcripts_fts) VALUES ('rebuild')"))
+            indexed = conn.execute(text("SELECT COUNT(*) FROM transcripts")).scalar() or 0
+    except Exception as exc:  # e.g. SQLite built without FTS5
+        raise HTTPException(status_code=500, detail=f"Reindex failed: {exc}")
+
+    return {"indexed": int(indexed)}
@@ -1,14 +1,66 @@
 from __future__ import annotations
 
+from datetime import datetime
+
 from fastapi import APIRouter, Depends, HTTPException
 from sqlalchemy.orm import Session
 
 from app.db import get_db
-from app.models import Job
+from app.models import Job, Upload
 from app.schemas import JobStats, JobStatus
 
 router = APIRouter(prefix="/jobs", tags=["jobs"])
 
+_PHASE_LABELS = {
+    "chunking": "Preparing audio",
+    "transcribing": "Transcribing",
+    "summarizing": "Generating summary",
+    "action_items": "Extracting action items",
+}
+
+
+def _job_message(j: Job) -> str:
+    """Human-friendly status line for the UI."""
+    if j.status == "queued":
+        return "Waiting in queue"
+    if j.status == "failed":
+        return j.error or "Job failed"
+    if j.status == "canceled":
+        return "Canceled"
+    if j.status == "completed":
+        return "Completed successfully"
+    if j.phase == "transcribing" and j.current_chunk and j.total_chunks:
+        return f"Transcribing chunk {j.current_chunk} of {j.total_chunks}"
+    return _PHASE_LABELS.get(j.phase or "", "Processing")
+
+
+def job_to_status(j: Job) -> JobStatus:
+    """Build the API representation of a job row."""
+    return JobStatus(
+        id=j.id,
+        upload_id=j.upload_id,
+        kind=j.kind,
+        status=j.status,
+        phase=j.phase,
+        progress=j.progress,
+        total_chunks=j.total_chunks,
+        current_chunk=j.current_chunk,
+        error=j.error,
+        message=_job_message(j),
+        options={
+            "summarize": j.summarize,
+            "action_items": j.generate_action_items,
+            "llm_model": j.llm_model,
+            "language": j.language,
+            "chunk_seconds": j.chunk_seconds,
+            "trim_silence": j.trim_silence,
+            "priority": j.priority,
+        },
+        created_at=j.created_at,
+        started_at=j.started_at,
+        finished_at=j.finished_at,
+    )
+
 
 @router.get("/stats", response_model=JobStats)
 def get_job_stats(db: Session = Depends(get_db)) -> JobStats:
@@ -25,22 +77,40 @@ def list_active_jobs(db: Session = Depends(get_db)) -> list[JobStatus]:
         .order_by(Job.created_at.asc())
         .all()
     )
-    return [
-        JobStatus(
-            id=j.id,
-            upload_id=j.upload_id,
-            status=j.status,
-            phase=j.phase,
-            progress=j.progress,
-            total_chunks=j.total_chunks,
-            current_chunk=j.current_chunk,
-            error=j.error,
-            created_at=j.created_at,
-            started_at=j.started_at,
-            finished_at=j.finished_at,
-        )
-        for j in jobs
-    ]
+    return [job_to_status(j) for j in jobs]
+
+
+@router.get("/status", response_model=dict[int, JobStatus])
+def batch_job_status(upload_ids: str, db: Session = Depends(get_db)) -> dict[int, JobStatus]:
+    """
+    Latest job status for several uploads in one call, e.g.
+    GET /api/jobs/status?upload_ids=1,2,3. Uploads without jobs are omitted.
+    """
+    ids: list[int] = []
+    for raw in upload_ids.split(","):
+        raw = raw.strip()
+        if not raw:
+            continue
+        try:
+            ids.append(int(raw))
+        except ValueError:
+            raise HTTPException(status_code=400, detail=f"Invalid upload id: {raw!r}")
+    if not ids:
+        raise HTTPException(status_code=400, detail="upload_ids is required")
+    if len(ids) > 200:
+        raise HTTPException(status_code=400, detail="At most 200 upload ids per request")
+
+    jobs = (
+        db.query(Job)
+        .filter(Job.upload_id.in_(ids))
+        .order_by(Job.upload_id.asc(), Job.id.asc())
+        .all()
+    )
+    # Later rows overwrite earlier ones, leaving the latest job per upload.
+    out: dict[int, JobStatus] = {}
+    for j in jobs:
+        out[j.upload_id] = job_to_status(j)
+    return out
 
 
 @router.get("/{job_id}", response_model=JobStatus)
@@ -48,17 +118,52 @@ def get_job(job_id: int, db: Session = Depends(get_db)) -> JobStatus:
     job = db.query(Job).filter(Job.id == job_id).first()
     if not job:
         raise HTTPException(status_code=404, detail="Job not found")
-    return JobStatus(
-        id=job.id,
-        upload_id=job.upload_id,
-        status=job.status,
-        phase=job.phase,
-        progress=job.progress,
-        total_chunks=job.total_chunks,
-        current_chunk=job.current_chunk,
-        error=job.error,
-        created_at=job.created_at,
-        started_at=job.started_at,
-        finished_at=job.finished_at,
-    )
+    return job_to_status(job)
+
+
+@router.get("/{job_id}/report")
+def get_job_report(job_id: int, db: Session = Depends(get_db)) -> dict:
+    """
+    Consolidated diagnostic report for one job: options used, chunk
+    progress, timings and the final error, in a single JSON document that
+    can be attached to a bug report as-is.
+    """
+    j = db.query(Job).filter(Job.id == job_id).first()
+    if not j:
+        raise HTTPException(status_code=404, detail="Job not found")
+    u = db.query(Upload).filter(Upload.id == j.upload_id).first()
+
+    queue_seconds = (j.started_at - j.created_at).total_seconds() if j.started_at else None
+    run_end = j.finished_at or (datetime.utcnow() if j.status == "processing" else None)
+    run_seconds = (run_end - j.started_at).total_seconds() if j.started_at and run_end else None
+
+    status = job_to_status(j)
+    return {
+        "job_id": j.id,
+        "upload_id": j.upload_id,
+        "kind": j.kind,
+        "status": j.status,
+        "options": status.options,
+        "upload": {
+            "display_name": u.display_name,
+            "original_filename": u.original_filename,
+            "size_bytes": u.size_bytes,
+            "duration_seconds": u.duration_seconds,
+            "language": u.language,
+        } if u else None,
+        "chunks": {
+            "total": j.total_chunks,
+            "current": j.current_chunk,
+            "completed": len(j.chunk_state or []),
+            "chunk_seconds": j.chunk_seconds,
+        },
+        "timings": {
+            "created_at": j.created_at,
+            "started_at": j.started_at,
+            "finished_at": j.finished_at,
+            "queue_seconds": round(queue_seconds, 2) if queue_seconds is not None else None,
+            "run_seconds": round(run_seconds, 2) if run_seconds is not None else None,
+        },
+        "error": j.error,
+    }
 
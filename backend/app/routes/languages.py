@@ -0,0 +1,16 @@
+from __future__ import annotations
+
+from fastapi import APIRouter
+
+from app.languages import LANGUAGES
+
+router = APIRouter(prefix="/languages", tags=["languages"])
+
+
+@router.get("")
+def list_languages() -> list[dict]:
+    """
+    Canonical language code/name pairs the server recognizes. The UI's
+    language dropdown should read from here rather than hard-coding a list.
+    """
+    return [{"code": code, "name": name} for code, name in sorted(LANGUAGES.items())]
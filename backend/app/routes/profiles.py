@@ -0,0 +1,181 @@
+from __future__ import annotations
+
+from datetime import date, datetime, timedelta
+
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy import func
+from sqlalchemy.orm import Session
+
+from app.config import settings
+from app.db import SessionLocal, get_db, get_read_db
+from app.languages import normalize_language
+from app.models import Profile, Upload
+from app.schemas import ProfileOut, ProfileUpdateRequest
+
+router = APIRouter(prefix="/profiles", tags=["profiles"])
+
+DEFAULT_PROFILE_NAME = "default"
+
+
+def ensure_default_profile(db: Session) -> Profile | None:
+    """
+    First-run bootstrap: create the default profile on an empty DB so the UI
+    has something to upload into. A DB that already has profiles — even if
+    they're all deactivated — is not first-run; deactivation was a choice,
+    so no row is fabricated and None is returned when none is active.
+    Idempotent.
+    """
+    p = (
+        db.query(Profile)
+        .filter(Profile.is_active == True)
+        .order_by(Profile.id.asc())
+        .first()
+    )
+    if p:
+        return p
+    if db.query(Profile.id).first():
+        return None
+    p = Profile(name=DEFAULT_PROFILE_NAME, is_active=True)
+    db.add(p)
+    db.commit()
+    db.refresh(p)
+    return p
+
+
+def _profile_out(p: Profile) -> ProfileOut:
+    return ProfileOut(
+        id=p.id,
+        name=p.name,
+        is_active=p.is_active,
+        default_language=p.default_language,
+        default_summarize=p.default_summarize,
+        default_action_items=p.default_action_items,
+        default_chunk_seconds=p.default_chunk_seconds,
+        created_at=p.created_at,
+    )
+
+
+@router.get("", response_model=list[ProfileOut])
+def list_profiles(
+    q: str | None = None,
+    include_inactive: bool = False,
+    limit: int = 0,
+    offset: int = 0,
+    db: Session = Depends(get_read_db),
+) -> list[ProfileOut]:
+    # Bootstrap on first run so an empty DB still yields a usable profile —
+    # but only when AUTO_CREATE_DEFAULT_PROFILE allows it; with the flag off
+    # profile management is explicit. The bootstrap writes, so it runs on
+    # its own primary-pool session; the read pool may point at a replica.
+    if settings.auto_create_default_profile:
+        wdb = SessionLocal()
+        try:
+            ensure_default_profile(wdb)
+        finally:
+            wdb.close()
+    qry = db.query(Profile)
+    if not include_inactive:
+        qry = qry.filter(Profile.is_active == True)
+    if q and q.strip():
+        qry = qry.filter(func.lower(Profile.name).like(f"%{q.strip().lower()}%"))
+    qry = qry.order_by(Profile.id.asc())
+    if offset > 0:
+        qry = qry.offset(offset)
+    if limit > 0:
+        qry = qry.limit(limit)
+    return [_profile_out(p) for p in qry.all()]
+
+
+def _set_profile_active(db: Session, profile_id: int, active: bool) -> ProfileOut:
+    p = db.query(Profile).filter(Profile.id == profile_id).first()
+    if not p:
+        raise HTTPException(status_code=404, detail="Profile not found")
+    p.is_active = active
+    db.commit()
+    db.refresh(p)
+    return _profile_out(p)
+
+
+@router.post("/{profile_id}/deactivate", response_model=ProfileOut)
+def deactivate_profile(profile_id: int, db: Session = Depends(get_db)) -> ProfileOut:
+    """
+    Hide a profile without deleting it: it drops out of the default listing
+    and stops receiving uploads, but its conversations remain intact.
+    """
+    return _set_profile_active(db, profile_id, False)
+
+
+@router.post("/{profile_id}/activate", response_model=ProfileOut)
+def activate_profile(profile_id: int, db: Session = Depends(get_db)) -> ProfileOut:
+    return _set_profile_active(db, profile_id, True)
+
+
+def _parse_date(value: str, name: str) -> date:
+    try:
+        return date.fromisoformat(value)
+    except ValueError:
+        raise HTTPException(status_code=400, detail=f"{name} must be a YYYY-MM-DD date")
+
+
+@router.get("/{profile_id}/usage")
+def profile_usage(
+    profile_id: int,
+    from_date: str = Query(..., alias="from"),
+    to_date: str = Query(..., alias="to"),
+    db: Session = Depends(get_read_db),
+) -> dict:
+    """
+    Transcribed time for the profile over a date range (inclusive): total
+    seconds plus a per-day breakdown, grouped in SQL. Uploads without a
+    duration (still processing, failed) count as zero.
+    """
+    start = _parse_date(from_date, "from")
+    end = _parse_date(to_date, "to")
+    if start > end:
+        raise HTTPException(status_code=400, detail="from must not be after to")
+    if not db.query(Profile.id).filter(Profile.id == profile_id).scalar():
+        raise HTTPException(status_code=404, detail="Profile not found")
+
+    day = func.date(Upload.created_at)
+    rows = (
+        db.query(day.label("day"), func.sum(func.coalesce(Upload.duration_seconds, 0.0)))
+        .filter(
+            Upload.profile_id == profile_id,
+            Upload.created_at >= datetime.combine(start, datetime.min.time()),
+            Upload.created_at < datetime.combine(end + timedelta(days=1), datetime.min.time()),
+        )
+        .group_by(day)
+        .order_by(day.asc())
+        .all()
+    )
+    days = [{"day": d, "seconds": round(float(s or 0.0), 2)} for d, s in rows]
+    return {
+        "profile_id": profile_id,
+        "from": start.isoformat(),
+        "to": end.isoformat(),
+        "total_seconds": round(sum(e["seconds"] for e in days), 2),
+        "days": days,
+    }
+
+
+@router.patch("/{profile_id}", response_model=ProfileOut)
+def update_profile(profile_id: int, req: ProfileUpdateRequest, db: Session = Depends(get_db)) -> ProfileOut:
+    p = db.query(Profile).filter(Profile.id == profile_id).first()
+    if not p:
+        raise HTTPException(status_code=404, detail="Profile not found")
+    if req.name is not None:
+        p.name = req.name.strip()
+    if req.default_language is not None:
+        lang = normalize_language(req.default_language)
+        if not lang:
+            raise HTTPException(status_code=400, detail=f"Unknown language code: {req.default_language!r}")
+        p.default_language = lang
+    if req.default_summarize is not None:
+        p.default_summarize = req.default_summarize
+    if req.default_action_items is not None:
+        p.default_action_items = req.default_action_items
+    if req.default_chunk_seconds is not None:
+        p.default_chunk_seconds = req.default_chunk_seconds
+    db.commit()
+    db.refresh(p)
+    return _profile_out(p)
@@ -1,32 +1,113 @@
 from __future__ import annotations
 
+import csv
+import hashlib
+import io
+import json
+import logging
 import os
+import shutil
+import threading
+import time
 from datetime import datetime
 from typing import Optional
 
-from fastapi import APIRouter, Depends, File, Form, HTTPException, UploadFile
-from fastapi.responses import FileResponse
-from sqlalchemy import func, or_
+from fastapi import APIRouter, Depends, File, Form, HTTPException, UploadFile, WebSocket, WebSocketDisconnect
+from fastapi.concurrency import run_in_threadpool
+from fastapi.responses import FileResponse, StreamingResponse
+from sqlalchemy import String, cast, func, or_, text as sql_text
 from sqlalchemy.orm import Session
 
 from app.config import settings
-from app.db import get_db
-from app.models import Job, Prompt, Transcript, TranscriptSegment, Upload
+from app.db import ReadSessionLocal, SessionLocal, get_db, get_read_db
+from app.formats import AUDIO_FORMATS, extension_supported
+from app.languages import normalize_language
+from app.models import Job, Profile, Prompt, Transcript, TranscriptSegment, TranscriptVersion, Upload
+from app.routes.jobs import job_to_status
+from app.routes.profiles import ensure_default_profile
+from app.services.audio import cached_transcode, transcode_supported
+from app.services.subtitles import parse_subtitles
+from app.services.transcripts import record_transcript_version
+from app.services.waveform import cached_waveform
 from app.schemas import (
+    ChapterOut,
+    LanguageUpdateRequest,
+    RetranscribeRangeRequest,
+    TranscriptFullOut,
+    TranscriptImportRequest,
     TranscriptSegmentOut,
+    TranscriptTurnOut,
+    TranscriptUpdateRequest,
+    TranscriptVersionOut,
     UploadCreateResponse,
     UploadDetail,
+    UploadDraftRequest,
+    UploadDraftResponse,
+    UploadDuplicateRequest,
     UploadListItem,
     UploadReprocessRequest,
     UploadReprocessResponse,
     UploadUpdateRequest,
 )
-from app.services.storage import ensure_dir, safe_filename, delete_tree
+from app.services.storage import build_storage_path, derive_display_name, ensure_dir, safe_filename, delete_tree, sniff_audio_type
 from worker.celery_app import celery_app
 
+logger = logging.getLogger(__name__)
+
 router = APIRouter(prefix="/uploads", tags=["uploads"])
 
 
+# TTL cache for the detail endpoint (DETAIL_CACHE_TTL_SECONDS, off by
+# default). API-side mutations invalidate eagerly; worker-side changes are
+# bounded by the TTL.
+_DETAIL_CACHE: dict[int, tuple[float, UploadDetail]] = {}
+_DETAIL_CACHE_LOCK = threading.Lock()
+
+
+def _detail_cache_get(upload_id: int) -> UploadDetail | None:
+    ttl = settings.detail_cache_ttl_seconds
+    if ttl <= 0:
+        return None
+    with _DETAIL_CACHE_LOCK:
+        entry = _DETAIL_CACHE.get(upload_id)
+        if not entry:
+            return None
+        cached_at, detail = entry
+        if time.monotonic() - cached_at > ttl:
+            del _DETAIL_CACHE[upload_id]
+            return None
+        return detail
+
+
+def _detail_cache_put(upload_id: int, detail: UploadDetail) -> None:
+    if settings.detail_cache_ttl_seconds <= 0:
+        return
+    with _DETAIL_CACHE_LOCK:
+        _DETAIL_CACHE[upload_id] = (time.monotonic(), detail)
+
+
+def _detail_cache_invalidate(upload_id: int) -> None:
+    with _DETAIL_CACHE_LOCK:
+        _DETAIL_CACHE.pop(upload_id, None)
+
+
+# Bytes received so far for in-flight uploads, keyed by upload id. Only
+# meaningful within a single API process; gives the UI an ingest bar while
+# the multipart body is still streaming in.
+_INGEST_PROGRESS: dict[int, int] = {}
+
+
+def _get_upload_or_404(db: Session, upload_id: int) -> Upload:
+    """
+    Single place that translates a missing upload row into a 404, instead of
+    every handler repeating the lookup-and-raise dance.
+    """
+    u = db.query(Upload).filter(Upload.id == upload_id).first()
+    if not u:
+        raise HTTPException(status_code=404, detail="Upload not found")
+    return u
+
+
 def _parse_tags_csv(s: str | None) -> list[str]:
     if not s:
         return []
@@ -66,21 +147,181 @@ def _normalize_tags(tags: list[str] | None) -> str | None:
     out = out[:20]
     return ",".join(out) if out else ""
 
+# Segments below this confidence count as "low" in transcript quality
+# summaries; stretches of them usually deserve a manual review pass.
+_LOW_CONFIDENCE = 0.5
+
+# Job priorities (0..10, higher runs first). Short clips get a bump so they
+# don't wait behind multi-hour recordings; interactive work (range
+# retranscribes) jumps the queue outright. Explicit form values win.
+_PRIORITY_DEFAULT = 5
+_PRIORITY_SHORT = 7
+_PRIORITY_INTERACTIVE = 9
+_SHORT_UPLOAD_BYTES = 20 * 1024 * 1024
+
+
+def _default_priority(size_bytes: int | None) -> int:
+    if size_bytes is not None and 0 < size_bytes <= _SHORT_UPLOAD_BYTES:
+        return _PRIORITY_SHORT
+    return _PRIORITY_DEFAULT
+
+
+def _validate_priority(priority: int | None) -> None:
+    if priority is not None and not (0 <= priority <= 10):
+        raise HTTPException(status_code=400, detail="priority must be between 0 and 10")
+
+
+def _resolve_profile(db: Session, profile_id: int | None) -> Profile:
+    """
+    Resolve the target profile for a new upload. Invalid or inactive ids
+    fall back to the default profile, unless AUTO_CREATE_DEFAULT_PROFILE is
+    off — then a valid active profile_id is required.
+    """
+    profile = None
+    if profile_id is not None:
+        # Deactivated profiles don't accept new uploads.
+        profile = (
+            db.query(Profile)
+            .filter(Profile.id == profile_id, Profile.is_active == True)
+            .first()
+        )
+    if profile:
+        return profile
+    if not settings.auto_create_default_profile:
+        raise HTTPException(status_code=400, detail="profile_id required")
+    default = ensure_default_profile(db)
+    if default is None:
+        # Profiles exist but all are deactivated; picking one silently would
+        # undo that choice, so the caller has to name (or reactivate) one.
+        raise HTTPException(status_code=400, detail="profile_id required (no active profile)")
+    return default
+
+
+def _reject_if_active_job(db: Session, upload_id: int) -> None:
+    """
+    Per-conversation guard for endpoints that rewrite transcript data: two
+    concurrent runs would double-write segments. The job table itself is the
+    lock (status queued/processing), so the check holds across API workers
+    and the worker process — an in-process mutex wouldn't.
+    """
+    active = (
+        db.query(Job)
+        .filter(Job.upload_id == upload_id, Job.status.in_(["queued", "processing"]))
+        .first()
+    )
+    if active:
+        raise HTTPException(
+            status_code=409,
+            detail=f"Job {active.id} is already {active.status} for this conversation; wait for it to finish",
+        )
+
+
+def _check_profile_job_cap(db: Session, profile_id: int | None) -> None:
+    """Enforce MAX_ACTIVE_JOBS_PER_PROFILE at enqueue time (429 when over)."""
+    cap = settings.max_active_jobs_per_profile
+    if cap <= 0 or profile_id is None:
+        return
+    active = (
+        db.query(Job)
+        .join(Upload, Upload.id == Job.upload_id)
+        .filter(Upload.profile_id == profile_id, Job.status.in_(["queued", "processing"]))
+        .count()
+    )
+    if active >= cap:
+        raise HTTPException(
+            status_code=429,
+            detail=f"Profile has {active} active jobs (limit {cap}); retry once some finish",
+            headers={"Retry-After": "30"},
+        )
+
+
+def _pipeline_unavailable_reason() -> str | None:
+    """
+    Check at request time that transcription can actually run, so uploads
+    don't create jobs that are doomed to fail asynchronously.
+    """
+    if shutil.which("ffmpeg") is None:
+        return "ffmpeg binary not found"
+    model = settings.whisper_model or ""
+    # Model can be a name (downloaded on demand) or a local path; only paths
+    # can be validated up front.
+    if (os.sep in model or model.startswith(".")) and not os.path.exists(model):
+        return f"whisper model path not found: {model}"
+    return None
+
+
+def _fts_matching_upload_ids(db: Session, phrase: str) -> list[int] | None:
+    """
+    Upload ids whose transcript matches the phrase via the transcripts_fts
+    index (kept in sync by triggers, see ensure_schema). Returns None when
+    FTS is unavailable (SQLite built without FTS5) so the caller can stick
+    to metadata-only matching.
+    """
+    # Quote as a single phrase so user input can't inject MATCH operators.
+    quoted = '"' + phrase.replace('"', '""') + '"'
+    try:
+        rows = db.execute(
+            sql_text(
+                "SELECT t.upload_id FROM transcripts_fts f "
+                "JOIN transcripts t ON t.id = f.rowid "
+                "WHERE transcripts_fts MATCH :q"
+            ),
+            {"q": quoted},
+        ).fetchall()
+    except Exception:
+        return None
+    return [r[0] for r in rows]
+
+
 @router.get("", response_model=list[UploadListItem])
-def list_uploads(q: Optional[str] = None, db: Session = Depends(get_db)) -> list[UploadListItem]:
+def list_uploads(
+    q: Optional[str] = None,
+    favorites: bool = False,
+    keyword: Optional[str] = None,
+    with_status: bool = False,
+    db: Session = Depends(get_read_db),
+) -> list[UploadListItem]:
     qry = db.query(Upload)
+    if favorites:
+        qry = qry.filter(Upload.is_favorite == True)
+    if keyword:
+        kw = keyword.strip().lower()
+        if kw:
+            # keywords is a JSON array of lowercase strings; match on the
+            # serialized form to avoid a json1 dependency.
+            qry = qry.filter(cast(Upload.keywords, String).like(f'%"{kw}"%'))
     if q:
         qn = q.strip().lower()
         if qn:
             like = f"%{qn}%"
-            qry = qry.filter(
-                or_(
-                    func.lower(Upload.display_name).like(like),
-                    func.lower(Upload.original_filename).like(like),
-                    func.lower(func.coalesce(Upload.tags, "")).like(like),
-                )
-            )
+            conds = [
+                func.lower(Upload.display_name).like(like),
+                func.lower(Upload.original_filename).like(like),
+                func.lower(func.coalesce(Upload.tags, "")).like(like),
+                func.lower(func.coalesce(Upload.notes, "")).like(like),
+            ]
+            # Transcript content goes through the FTS index rather than a
+            # LIKE over the full text of every conversation.
+            fts_ids = _fts_matching_upload_ids(db, qn)
+            if fts_ids:
+                conds.append(Upload.id.in_(fts_ids))
+            qry = qry.filter(or_(*conds))
     rows = qry.order_by(Upload.created_at.desc()).all()
+
+    # Latest job status per upload (?with_status=true), fetched with one IN
+    # query instead of one per row. Ascending order means later jobs
+    # overwrite earlier ones in the dict.
+    status_by_upload: dict[int, str] = {}
+    if with_status and rows:
+        jrows = (
+            db.query(Job.upload_id, Job.status)
+            .filter(Job.upload_id.in_([u.id for u in rows]))
+            .order_by(Job.id.asc())
+            .all()
+        )
+        for uid, st in jrows:
+            status_by_upload[uid] = st
+
     return [
         UploadListItem(
             id=u.id,
@@ -90,56 +331,653 @@ def list_uploads(q: Optional[str] = None, db: Session = Depends(get_db)) -> list
             duration_seconds=u.duration_seconds,
             language=u.language,
             tags=_parse_tags_csv(u.tags),
+            is_favorite=u.is_favorite,
+            job_status=status_by_upload.get(u.id),
         )
         for u in rows
     ]
 
 
 @router.get("/{upload_id}", response_model=UploadDetail)
-def get_upload(upload_id: int, db: Session = Depends(get_db)) -> UploadDetail:
-    u = db.query(Upload).filter(Upload.id == upload_id).first()
-    if not u:
-        raise HTTPException(status_code=404, detail="Upload not found")
+def get_upload(upload_id: int, include_job: bool = False, db: Session = Depends(get_read_db)) -> UploadDetail:
+    # Only the plain detail shape is cached; embedded job status changes too
+    # frequently to be worth it.
+    if not include_job:
+        cached = _detail_cache_get(upload_id)
+        if cached is not None:
+            return cached
+
+    u = _get_upload_or_404(db, upload_id)
+
+    # Saves the UI a follow-up /jobs call on the detail page.
+    job_out = None
+    if include_job:
+        latest = (
+            db.query(Job)
+            .filter(Job.upload_id == upload_id)
+            .order_by(Job.id.desc())
+            .first()
+        )
+        if latest:
+            job_out = job_to_status(latest)
 
     tr = db.query(Transcript).filter(Transcript.upload_id == upload_id).first()
-    return UploadDetail(
+    detail = UploadDetail(
         id=u.id,
         display_name=u.display_name,
         original_filename=u.original_filename,
         created_at=u.created_at,
         duration_seconds=u.duration_seconds,
+        trim_start_seconds=u.trim_start_seconds,
+        trim_end_seconds=u.trim_end_seconds,
         language=u.language,
         tags=_parse_tags_csv(u.tags),
+        is_favorite=u.is_favorite,
+        keywords=list(u.keywords or []),
+        notes=u.notes,
         summary=u.summary,
         action_items=u.action_items,
         transcript_text=tr.text if tr else None,
+        transcript_status=tr.status if tr else None,
+        job=job_out,
     )
+    if not include_job:
+        _detail_cache_put(upload_id, detail)
+    return detail
+
+
+@router.get("/{upload_id}/progress")
+def get_ingest_progress(upload_id: int, db: Session = Depends(get_read_db)) -> dict:
+    """
+    Bytes received so far for an upload whose body is still streaming in,
+    separate from transcription progress. Once the file is fully stored the
+    response flips to done with the final size.
+    """
+    received = _INGEST_PROGRESS.get(upload_id)
+    if received is not None:
+        return {"upload_id": upload_id, "received_bytes": received, "done": False}
+    u = _get_upload_or_404(db, upload_id)
+    return {"upload_id": upload_id, "received_bytes": u.size_bytes or 0, "done": bool(u.stored_path)}
+
+
+@router.get("/{upload_id}/segments")
+def get_segments(upload_id: int, min_confidence: Optional[float] = None, db: Session = Depends(get_read_db)):
+    """
+    Stream the segment list as a JSON array (same shape as
+    ``list[TranscriptSegmentOut]``). Long recordings can have thousands of
+    segments; streaming from the cursor avoids building the full list in
+    memory and improves time-to-first-byte.
+
+    ?min_confidence= drops segments below the threshold; segments without a
+    confidence value (imports, pre-upgrade rows) are kept, since unknown
+    isn't the same as low.
 
+    Missing upload or transcript is 404; a transcript with zero segments is
+    a legitimate 200 with an empty array.
+    """
+    if min_confidence is not None and not (0.0 <= min_confidence <= 1.0):
+        raise HTTPException(status_code=400, detail="min_confidence must be between 0 and 1")
+    u = _get_upload_or_404(db, upload_id)
+    tr = db.query(Transcript).filter(Transcript.upload_id == upload_id).first()
+    if not tr:
+        raise HTTPException(status_code=404, detail="Transcript not found")
+
+    def generate():
+        db = ReadSessionLocal()
+        try:
+            qry = (
+                db.query(TranscriptSegment)
+                .filter(TranscriptSegment.upload_id == upload_id)
+                .order_by(TranscriptSegment.start_time.asc())
+            )
+            if min_confidence is not None:
+                qry = qry.filter(
+                    or_(
+                        TranscriptSegment.confidence == None,
+                        TranscriptSegment.confidence >= min_confidence,
+                    )
+                )
+            rows = qry.yield_per(500)
+            yield "["
+            first = True
+            for s in rows:
+                item = json.dumps(
+                    {
+                        "id": s.id,
+                        "start_time": s.start_time,
+                        "end_time": s.end_time,
+                        "text": s.text,
+                        "kind": s.kind,
+                        "confidence": s.confidence,
+                    }
+                )
+                yield item if first else "," + item
+                first = False
+            yield "]"
+        except GeneratorExit:
+            # Client went away mid-stream; stop iterating the cursor now
+            # rather than finishing the scan for nobody.
+            logger.debug("segment stream for upload %s aborted by client", upload_id)
+            raise
+        finally:
+            db.close()
+
+    return StreamingResponse(generate(), media_type="application/json")
+
+
+@router.websocket("/{upload_id}/live")
+async def live_transcript(websocket: WebSocket, upload_id: int):
+    """
+    Push newly transcribed segments as JSON frames while a job is running.
+
+    The worker commits each chunk's segments as it finishes, so polling the
+    DB is enough to stream them live (the API and worker are separate
+    processes; the DB is their only shared channel). The socket closes with
+    a final "done" frame once the latest job reaches a terminal state.
+    """
+    import asyncio
+
+    def _poll(since_id: int) -> tuple[list[dict], str | None, str | None]:
+        # Synchronous SQLAlchemy work; runs in the threadpool so a slow
+        # query (large transcript, SQLite writer lock) doesn't stall the
+        # event loop for every other request.
+        db = SessionLocal()
+        try:
+            rows = (
+                db.query(TranscriptSegment)
+                .filter(TranscriptSegment.upload_id == upload_id, TranscriptSegment.id > since_id)
+                .order_by(TranscriptSegment.id.asc())
+                .all()
+            )
+            job = (
+                db.query(Job)
+                .filter(Job.upload_id == upload_id)
+                .order_by(Job.id.desc())
+                .first()
+            )
+            frames = [
+                {
+                    "event": "segment",
+                    "id": s.id,
+                    "start_time": s.start_time,
+                    "end_time": s.end_time,
+                    "text": s.text,
+                }
+                for s in rows
+            ]
+            return frames, (job.status if job else None), (job.error if job else None)
+        finally:
+            db.close()
+
+    await websocket.accept()
+    last_id = 0
+    try:
+        while True:
+            frames, job_status, job_error = await run_in_threadpool(_poll, last_id)
+
+            for frame in frames:
+                last_id = frame["id"]
+                await websocket.send_json(frame)
+
+            if job_status is None or job_status in ("completed", "completed_with_errors", "failed", "canceled"):
+                await websocket.send_json(
+                    {
+                        "event": "done",
+                        "status": job_status or "unknown",
+                        "error": job_error,
+                    }
+                )
+                break
 
-@router.get("/{upload_id}/segments", response_model=list[TranscriptSegmentOut])
-def get_segments(upload_id: int, db: Session = Depends(get_db)) -> list[TranscriptSegmentOut]:
+            await asyncio.sleep(1.0)
+    except WebSocketDisconnect:
+        return
+    await websocket.close()
+
+
+@router.get("/{upload_id}/transcript/segments.csv")
+def export_segments_csv(upload_id: int, db: Session = Depends(get_read_db)):
+    """
+    Stream segments as CSV (start,end,speaker,kind,confidence,text) for use
+    in spreadsheets. Speaker stays blank until diarization lands; confidence
+    is blank for rows that predate the column.
+    """
+    u = _get_upload_or_404(db, upload_id)
+
+    def generate():
+        buf = io.StringIO()
+        writer = csv.writer(buf)
+        writer.writerow(["start", "end", "speaker", "kind", "confidence", "text"])
+        yield buf.getvalue()
+
+        db2 = ReadSessionLocal()
+        try:
+            rows = (
+                db2.query(TranscriptSegment)
+                .filter(TranscriptSegment.upload_id == upload_id)
+                .order_by(TranscriptSegment.start_time.asc())
+                .yield_per(500)
+            )
+            for s in rows:
+                buf.seek(0)
+                buf.truncate(0)
+                confidence = "" if s.confidence is None else s.confidence
+                writer.writerow([s.start_time, s.end_time, "", s.kind, confidence, s.text])
+                yield buf.getvalue()
+        except GeneratorExit:
+            logger.debug("CSV export for upload %s aborted by client", upload_id)
+            raise
+        finally:
+            db2.close()
+
+    base = "".join(c if c.isalnum() or c in ("-", "_", " ") else "_" for c in u.display_name).strip() or "segments"
+    return StreamingResponse(
+        generate(),
+        media_type="text/csv",
+        headers={"Content-Disposition": f'attachment; filename="{base}.csv"'},
+    )
+
+
+@router.get("/{upload_id}/transcript/full", response_model=TranscriptFullOut)
+def get_transcript_full(upload_id: int, db: Session = Depends(get_read_db)) -> TranscriptFullOut:
+    """
+    Transcript with its segments embedded, for clients that always need
+    both — saves the second round trip over /transcript + /segments.
+    """
+    u = _get_upload_or_404(db, upload_id)
+    tr = db.query(Transcript).filter(Transcript.upload_id == upload_id).first()
+    if not tr:
+        raise HTTPException(status_code=404, detail="Transcript not found")
     rows = (
         db.query(TranscriptSegment)
         .filter(TranscriptSegment.upload_id == upload_id)
         .order_by(TranscriptSegment.start_time.asc())
         .all()
     )
-    return [TranscriptSegmentOut(id=s.id, start_time=s.start_time, end_time=s.end_time, text=s.text) for s in rows]
+    confs = [s.confidence for s in rows if s.confidence is not None]
+    return TranscriptFullOut(
+        upload_id=upload_id,
+        text=tr.text,
+        status=tr.status,
+        language=u.language,
+        avg_confidence=round(sum(confs) / len(confs), 4) if confs else None,
+        low_confidence_ratio=(
+            round(sum(1 for c in confs if c < _LOW_CONFIDENCE) / len(confs), 4) if confs else None
+        ),
+        segments=[
+            TranscriptSegmentOut(
+                id=s.id,
+                start_time=s.start_time,
+                end_time=s.end_time,
+                text=s.text,
+                kind=s.kind,
+                confidence=s.confidence,
+            )
+            for s in rows
+        ],
+    )
+
+
+@router.get("/{upload_id}/transcript/turns", response_model=list[TranscriptTurnOut])
+def get_transcript_turns(
+    upload_id: int,
+    gap_seconds: float = 2.0,
+    db: Session = Depends(get_read_db),
+) -> list[TranscriptTurnOut]:
+    """
+    Collapse consecutive segments into "turns". We don't have diarization, so
+    turns are split purely on time gaps above the threshold.
+    """
+    u = _get_upload_or_404(db, upload_id)
+    rows = (
+        db.query(TranscriptSegment)
+        .filter(TranscriptSegment.upload_id == upload_id)
+        .order_by(TranscriptSegment.start_time.asc())
+        .all()
+    )
+    turns: list[TranscriptTurnOut] = []
+    for s in rows:
+        if turns and (s.start_time - turns[-1].end_time) <= gap_seconds:
+            cur = turns[-1]
+            cur.end_time = max(cur.end_time, s.end_time)
+            cur.text = f"{cur.text} {s.text}".strip()
+        else:
+            turns.append(
+                TranscriptTurnOut(speaker=None, start_time=s.start_time, end_time=s.end_time, text=s.text)
+            )
+    return turns
+
+
+def _chapter_label(text: str, max_words: int = 8) -> str:
+    words = text.split()
+    label = " ".join(words[:max_words])
+    return f"{label}…" if len(words) > max_words else label
+
+
+# Silent-reading speed used for per-chapter reading-time estimates.
+_READING_WORDS_PER_MINUTE = 200
+
+
+@router.get("/{upload_id}/chapters", response_model=list[ChapterOut])
+def get_chapters(
+    upload_id: int,
+    gap_seconds: float = 30.0,
+    max_seconds: float = 600.0,
+    db: Session = Depends(get_read_db),
+) -> list[ChapterOut]:
+    """
+    Auto-generated chapter markers, computed on demand from the segments so
+    they never go stale across retranscribes. A new chapter starts at a
+    silence gap above gap_seconds, or once a chapter spans max_seconds.
+    Labels are the chapter's first words; reading_time_seconds estimates
+    how long the chapter's text takes to read rather than listen to.
+    """
+    if gap_seconds <= 0 or max_seconds <= 0:
+        raise HTTPException(status_code=400, detail="gap_seconds and max_seconds must be positive")
+    _get_upload_or_404(db, upload_id)
+    rows = (
+        db.query(TranscriptSegment)
+        .filter(TranscriptSegment.upload_id == upload_id)
+        .order_by(TranscriptSegment.start_time.asc())
+        .all()
+    )
+
+    chapters: list[ChapterOut] = []
+    word_counts: list[int] = []
+    for s in rows:
+        words = len(s.text.split()) if s.kind == "speech" else 0
+        starts_new = (
+            not chapters
+            or (s.start_time - chapters[-1].end_time) > gap_seconds
+            or (s.end_time - chapters[-1].start_time) > max_seconds
+        )
+        if starts_new:
+            chapters.append(
+                ChapterOut(
+                    start_time=s.start_time,
+                    end_time=s.end_time,
+                    # Prefer speech for the label; a non-speech opener keeps
+                    # its marker ([MUSIC]) until real speech arrives.
+                    label=_chapter_label(s.text) if s.kind == "speech" else s.text,
+                )
+            )
+            word_counts.append(words)
+            continue
+        cur = chapters[-1]
+        cur.end_time = max(cur.end_time, s.end_time)
+        word_counts[-1] += words
+        if s.kind == "speech" and cur.label.startswith(("[", "(")):
+            cur.label = _chapter_label(s.text)
+    for chapter, words in zip(chapters, word_counts):
+        chapter.reading_time_seconds = round(words * 60.0 / _READING_WORDS_PER_MINUTE, 1)
+    return chapters
+
+
+@router.get("/{upload_id}/transcript/search")
+def search_transcript(
+    upload_id: int,
+    q: str,
+    limit: int = 100,
+    db: Session = Depends(get_read_db),
+) -> dict:
+    """
+    Case-insensitive phrase search within one conversation's segments.
+    Matches include character offsets so the UI can highlight them and jump
+    to the segment's start time.
+    """
+    needle = q.strip().lower()
+    if not needle:
+        raise HTTPException(status_code=400, detail="q is required")
+    if not (1 <= limit <= 1000):
+        raise HTTPException(status_code=400, detail="limit must be between 1 and 1000")
+    _get_upload_or_404(db, upload_id)
+
+    rows = (
+        db.query(TranscriptSegment)
+        .filter(
+            TranscriptSegment.upload_id == upload_id,
+            func.lower(TranscriptSegment.text).like(f"%{needle}%"),
+        )
+        .order_by(TranscriptSegment.start_time.asc())
+        .limit(limit)
+        .all()
+    )
+
+    matches = []
+    for s in rows:
+        offsets = []
+        hay = s.text.lower()
+        pos = hay.find(needle)
+        while pos != -1:
+            offsets.append(pos)
+            pos = hay.find(needle, pos + 1)
+        matches.append(
+            {
+                "segment_id": s.id,
+                "start_time": s.start_time,
+                "end_time": s.end_time,
+                "text": s.text,
+                "offsets": offsets,
+            }
+        )
+    return {"query": q, "matches": matches}
+
+
+@router.post("/{upload_id}/transcript/import")
+def import_transcript(
+    upload_id: int,
+    req: TranscriptImportRequest,
+    replace: bool = False,
+    db: Session = Depends(get_db),
+) -> dict:
+    """
+    Attach an existing transcript (structured segments or SRT/VTT content)
+    without running whisper. Refuses to clobber an existing transcript
+    unless ?replace=true.
+    """
+    u = _get_upload_or_404(db, upload_id)
+    _reject_if_active_job(db, upload_id)
+
+    if (req.segments is None) == (req.subtitles is None):
+        raise HTTPException(status_code=400, detail="Provide exactly one of segments or subtitles")
+
+    existing = db.query(Transcript).filter(Transcript.upload_id == upload_id).first()
+    if existing and existing.text and not replace:
+        raise HTTPException(status_code=409, detail="Transcript already exists (use ?replace=true)")
+
+    if req.segments is not None:
+        parsed = [(s.start, s.end, s.text.strip()) for s in req.segments if s.text.strip()]
+    else:
+        parsed = parse_subtitles(req.subtitles or "")
+    if not parsed:
+        raise HTTPException(status_code=400, detail="No segments could be parsed")
+    parsed.sort(key=lambda c: c[0])
+
+    db.query(TranscriptSegment).filter(TranscriptSegment.upload_id == upload_id).delete()
+    for start, end, seg_text in parsed:
+        db.add(TranscriptSegment(upload_id=upload_id, start_time=start, end_time=end, text=seg_text))
+
+    transcript_text = " ".join(seg_text for _, _, seg_text in parsed).strip()
+    if existing:
+        existing.text = transcript_text
+        existing.status = "complete"
+    else:
+        db.add(Transcript(upload_id=upload_id, text=transcript_text, status="complete"))
+    record_transcript_version(db, upload_id, transcript_text, source="import")
+    db.commit()
+    _detail_cache_invalidate(upload_id)
+    return {"ok": True, "segments": len(parsed)}
+
+
+@router.put("/{upload_id}/transcript/language")
+def update_language(upload_id: int, req: LanguageUpdateRequest, db: Session = Depends(get_db)) -> dict:
+    """
+    Metadata-only correction for misdetected languages; no re-transcription.
+    """
+    u = _get_upload_or_404(db, upload_id)
+    code = normalize_language(req.language)
+    if not code:
+        raise HTTPException(status_code=400, detail=f"Unknown language code: {req.language}")
+    u.language = code
+    u.updated_at = datetime.utcnow()
+    db.commit()
+    _detail_cache_invalidate(upload_id)
+    return {"ok": True, "language": code}
+
+
+@router.patch("/{upload_id}/transcript")
+def update_transcript_fields(upload_id: int, req: TranscriptUpdateRequest, db: Session = Depends(get_db)) -> dict:
+    """
+    Partial metadata correction for a transcript: any subset of duration,
+    language and summary. Omitted fields are left untouched; unknown fields
+    are rejected by the schema. Complements /transcript/import.
+    """
+    u = _get_upload_or_404(db, upload_id)
+    updated: list[str] = []
+    if req.duration_seconds is not None:
+        u.duration_seconds = req.duration_seconds
+        updated.append("duration_seconds")
+    if req.language is not None:
+        code = normalize_language(req.language)
+        if not code:
+            raise HTTPException(status_code=400, detail=f"Unknown language code: {req.language}")
+        u.language = code
+        updated.append("language")
+    if req.summary is not None:
+        u.summary = req.summary
+        updated.append("summary")
+    if not updated:
+        raise HTTPException(status_code=400, detail="No fields to update")
+    u.updated_at = datetime.utcnow()
+    db.commit()
+    _detail_cache_invalidate(upload_id)
+    return {"ok": True, "updated": updated}
+
+
+@router.get("/{upload_id}/transcript/versions", response_model=list[TranscriptVersionOut])
+def list_transcript_versions(upload_id: int, db: Session = Depends(get_read_db)) -> list[TranscriptVersionOut]:
+    u = _get_upload_or_404(db, upload_id)
+    rows = (
+        db.query(TranscriptVersion)
+        .filter(TranscriptVersion.upload_id == upload_id)
+        .order_by(TranscriptVersion.version.asc())
+        .all()
+    )
+    return [
+        TranscriptVersionOut(
+            id=v.id,
+            version=v.version,
+            source=v.source,
+            text_length=len(v.text or ""),
+            created_at=v.created_at,
+        )
+        for v in rows
+    ]
+
+
+@router.post("/{upload_id}/transcript/versions/{version}/restore")
+def restore_transcript_version(upload_id: int, version: int, db: Session = Depends(get_db)) -> dict:
+    u = _get_upload_or_404(db, upload_id)
+    # A running job rewrites Transcript.text at completion and would clobber
+    # the restore (or have its result clobbered); same guard as reprocess.
+    _reject_if_active_job(db, upload_id)
+    v = (
+        db.query(TranscriptVersion)
+        .filter(TranscriptVersion.upload_id == upload_id, TranscriptVersion.version == version)
+        .first()
+    )
+    if not v:
+        raise HTTPException(status_code=404, detail="Transcript version not found")
+
+    tr = db.query(Transcript).filter(Transcript.upload_id == upload_id).first()
+    if tr:
+        # Snapshot the current text first so the restore itself can be undone.
+        record_transcript_version(db, upload_id, tr.text, source="restore")
+        tr.text = v.text
+    else:
+        tr = Transcript(upload_id=upload_id, text=v.text)
+        db.add(tr)
+    db.commit()
+    _detail_cache_invalidate(upload_id)
+    return {"ok": True, "restored_version": version}
+
+
+def _require_audio_file(u: Upload) -> str:
+    """
+    A missing row and a missing file are different failures: the latter means
+    the DB and the storage volume have drifted (manual deletion, lost mount),
+    which is worth logging loudly instead of reporting as a plain not-found.
+    Audio removed by the retention sweeper is a third case: expected, and
+    reported as 410 so clients can distinguish it.
+    """
+    if u.audio_deleted_at is not None:
+        raise HTTPException(
+            status_code=410,
+            detail="Audio expired (removed by AUDIO_RETENTION_DAYS); the transcript is still available",
+        )
+    if not u.stored_path or not os.path.exists(u.stored_path):
+        logger.error(
+            "upload %s exists in the DB but its audio file is missing (%s)",
+            u.id, u.stored_path or "<no stored_path>",
+        )
+        raise HTTPException(status_code=404, detail="Audio file missing from storage")
+    return u.stored_path
 
 
 @router.get("/{upload_id}/audio")
-def get_audio(upload_id: int, db: Session = Depends(get_db)):
-    u = db.query(Upload).filter(Upload.id == upload_id).first()
-    if not u or not u.stored_path or not os.path.exists(u.stored_path):
-        raise HTTPException(status_code=404, detail="Audio not found")
-    return FileResponse(u.stored_path, filename=u.original_filename)
+def get_audio(upload_id: int, format: Optional[str] = None, db: Session = Depends(get_read_db)):
+    """
+    Serve the stored audio. With ?format=mp3 the file is transcoded for
+    browser playback (flac/wma don't play natively everywhere); the result
+    is cached beside the audio so only the first request pays for ffmpeg.
+    """
+    u = _get_upload_or_404(db, upload_id)
+    path = _require_audio_file(u)
+    if format is None:
+        return FileResponse(path, filename=u.original_filename)
+    if not transcode_supported(format):
+        raise HTTPException(status_code=400, detail=f"Unsupported playback format: {format}")
+    cache_dir = os.path.join(settings.upload_dir, str(u.id))
+    try:
+        out_path, mime = cached_transcode(path, cache_dir, format)
+    except RuntimeError as e:
+        raise HTTPException(status_code=500, detail=str(e))
+    stem = os.path.splitext(u.original_filename or u.display_name)[0] or f"upload-{u.id}"
+    return FileResponse(out_path, media_type=mime, filename=f"{stem}.{format}")
+
+
+@router.get("/{upload_id}/waveform")
+def get_waveform(upload_id: int, buckets: int = 1000, db: Session = Depends(get_read_db)) -> dict:
+    """
+    Normalized amplitude peaks for the player's waveform display. Computed
+    with ffmpeg on first request and cached beside the audio, so repeat
+    loads are just a file read.
+    """
+    if not (10 <= buckets <= 10000):
+        raise HTTPException(status_code=400, detail="buckets must be between 10 and 10000")
+    u = _get_upload_or_404(db, upload_id)
+    path = _require_audio_file(u)
+    cache_dir = os.path.join(settings.upload_dir, str(u.id))
+    try:
+        peaks = cached_waveform(path, cache_dir, buckets)
+    except RuntimeError as e:
+        raise HTTPException(status_code=500, detail=str(e))
+    return {"upload_id": u.id, "buckets": buckets, "peaks": peaks}
+
+
+@router.post("/{upload_id}/favorite")
+def toggle_favorite(upload_id: int, db: Session = Depends(get_db)) -> dict:
+    u = _get_upload_or_404(db, upload_id)
+    u.is_favorite = not u.is_favorite
+    u.updated_at = datetime.utcnow()
+    db.commit()
+    _detail_cache_invalidate(upload_id)
+    return {"ok": True, "is_favorite": u.is_favorite}
 
 
 @router.patch("/{upload_id}")
 def update_upload(upload_id: int, req: UploadUpdateRequest, db: Session = Depends(get_db)) -> dict:
-    u = db.query(Upload).filter(Upload.id == upload_id).first()
-    if not u:
-        raise HTTPException(status_code=404, detail="Upload not found")
+    u = _get_upload_or_404(db, upload_id)
     changed = False
     if req.display_name is not None:
         u.display_name = req.display_name.strip()
@@ -147,10 +985,14 @@ def update_upload(upload_id: int, req: UploadUpdateRequest, db: Session = Depend
     if req.tags is not None:
         u.tags = _normalize_tags(req.tags)
         changed = True
+    if req.notes is not None:
+        u.notes = req.notes.strip() or None
+        changed = True
     if not changed:
         raise HTTPException(status_code=400, detail="No fields to update")
     u.updated_at = datetime.utcnow()
     db.commit()
+    _detail_cache_invalidate(upload_id)
     return {"ok": True}
 
 
@@ -160,11 +1002,34 @@ def delete_upload(upload_id: int, db: Session = Depends(get_db)) -> dict:
     if not u:
         return {"ok": True}
 
-    # best-effort file cleanup: delete upload directory
+    # Stop any in-flight work first so the worker doesn't keep transcribing
+    # an orphaned file and fail writing results for a deleted row.
+    active_jobs = (
+        db.query(Job)
+        .filter(Job.upload_id == upload_id, Job.status.in_(["queued", "processing"]))
+        .all()
+    )
+    for j in active_jobs:
+        if j.celery_task_id:
+            celery_app.control.revoke(j.celery_task_id, terminate=True)
+        j.status = "canceled"
+        j.progress = 100
+        j.finished_at = datetime.utcnow()
+    db.commit()
+
+    # best-effort file cleanup: stored file (layout may be templated) plus
+    # the per-upload work directory used for chunks
+    stored_path = u.stored_path
     upload_dir = os.path.join(settings.upload_dir, str(u.id))
     db.delete(u)
     db.commit()
+    if stored_path and os.path.exists(stored_path):
+        try:
+            os.remove(stored_path)
+        except OSError:
+            pass
     delete_tree(upload_dir)
+    _detail_cache_invalidate(upload_id)
     return {"ok": True}
 
 
@@ -172,8 +1037,14 @@ def delete_upload(upload_id: int, db: Session = Depends(get_db)) -> dict:
 def create_upload(
     file: UploadFile = File(...),
     display_name: Optional[str] = Form(None),
-    summarize: bool = Form(False),
-    action_items: bool = Form(False),
+    profile_id: Optional[int] = Form(None),
+    chunk_seconds: Optional[int] = Form(None),
+    trim_silence: bool = Form(False),
+    dedupe: bool = Form(False),
+    priority: Optional[int] = Form(None),
+    # None (omitted) lets per-profile defaults apply; explicit values win.
+    summarize: Optional[bool] = Form(None),
+    action_items: Optional[bool] = Form(None),
     llm_model: Optional[str] = Form(None),
     prompt_summary_id: Optional[int] = Form(None),
     prompt_action_items_id: Optional[int] = Form(None),
@@ -182,12 +1053,56 @@ def create_upload(
     if not file.filename:
         raise HTTPException(status_code=400, detail="Missing filename")
 
+    if not extension_supported(file.filename):
+        raise HTTPException(
+            status_code=400,
+            detail=f"Unsupported file type; supported extensions: {', '.join(sorted(AUDIO_FORMATS))}",
+        )
+
+    reason = _pipeline_unavailable_reason()
+    if reason:
+        raise HTTPException(
+            status_code=503,
+            detail=f"transcription not available: {reason}",
+            headers={"Retry-After": "60"},
+        )
+
+    if chunk_seconds is not None and not (5 <= chunk_seconds <= 300):
+        raise HTTPException(status_code=400, detail="chunk_seconds must be between 5 and 300")
+    _validate_priority(priority)
+
+    if settings.sniff_uploads:
+        head = file.file.read(512)
+        file.file.seek(0)
+        detected = sniff_audio_type(head)
+        if not detected:
+            raise HTTPException(
+                status_code=400,
+                detail=f"File content is not recognized audio (magic bytes: {head[:8].hex() or 'empty'})",
+            )
+
     ensure_dir(settings.upload_dir)
 
     # Create upload DB row first so we have an ID for storage path.
-    name = (display_name or file.filename).strip()
+    # An explicit display_name always wins; otherwise the title comes from
+    # the filename, cleaned up unless CLEAN_FILENAME_TITLES is off.
+    name = (display_name or "").strip()
+    if not name and settings.clean_filename_titles:
+        name = derive_display_name(file.filename)
     if not name:
-        name = file.filename
+        name = (file.filename or "").strip() or file.filename
+
+    profile = _resolve_profile(db, profile_id)
+
+    _check_profile_job_cap(db, profile.id)
+
+    # Fill omitted options from the profile's defaults (explicit fields win).
+    if summarize is None:
+        summarize = bool(profile.default_summarize)
+    if action_items is None:
+        action_items = bool(profile.default_action_items)
+    if chunk_seconds is None and profile.default_chunk_seconds:
+        chunk_seconds = profile.default_chunk_seconds
 
     u = Upload(
         original_filename=file.filename,
@@ -196,30 +1111,71 @@ def create_upload(
         content_type=file.content_type,
         size_bytes=None,
         tags=None,
+        profile_id=profile.id,
+        # Language hint from the profile; transcription overwrites it with
+        # the detected language once the job finishes.
+        language=profile.default_language,
     )
     db.add(u)
     db.commit()
     db.refresh(u)
 
-    # Save file to /uploads/<id>/<uuid>.<ext>
-    upload_dir = os.path.join(settings.upload_dir, str(u.id))
-    ensure_dir(upload_dir)
-    stored_name = safe_filename(file.filename)
-    stored_path = os.path.join(upload_dir, stored_name)
+    # Save file according to the configured layout (default: <id>/<uuid>.<ext>)
+    try:
+        stored_path = build_storage_path(
+            settings.upload_dir,
+            settings.upload_path_template,
+            upload_id=u.id,
+            profile_id=u.profile_id,
+            filename=file.filename,
+        )
+    except ValueError as e:
+        raise HTTPException(status_code=500, detail=str(e))
+    ensure_dir(os.path.dirname(stored_path))
 
     size = 0
-    with open(stored_path, "wb") as out:
-        while True:
-            chunk = file.file.read(1024 * 1024)
-            if not chunk:
-                break
-            out.write(chunk)
-            size += len(chunk)
+    hasher = hashlib.sha256()
+    _INGEST_PROGRESS[u.id] = 0
+    try:
+        with open(stored_path, "wb") as out:
+            while True:
+                chunk = file.file.read(1024 * 1024)
+                if not chunk:
+                    break
+                out.write(chunk)
+                hasher.update(chunk)
+                size += len(chunk)
+                _INGEST_PROGRESS[u.id] = size
+    finally:
+        _INGEST_PROGRESS.pop(u.id, None)
 
     u.stored_path = stored_path
     u.size_bytes = size
+    u.content_hash = hasher.hexdigest()
     db.commit()
 
+    if dedupe:
+        existing = (
+            db.query(Upload)
+            .filter(
+                Upload.content_hash == u.content_hash,
+                Upload.profile_id == u.profile_id,
+                Upload.id != u.id,
+            )
+            .order_by(Upload.id.asc())
+            .first()
+        )
+        if existing:
+            # Same bytes already transcribed for this profile: drop the new
+            # copy and point the client at the existing upload.
+            db.delete(u)
+            db.commit()
+            try:
+                os.remove(stored_path)
+            except OSError:
+                pass
+            return UploadCreateResponse(upload_id=existing.id, job_id=None, duplicate=True)
+
     # Validate prompt IDs if provided.
     if prompt_summary_id is not None:
         p = db.query(Prompt).filter(Prompt.id == prompt_summary_id, Prompt.kind == "summary").first()
@@ -232,34 +1188,283 @@ def create_upload(
 
     job = Job(
         upload_id=u.id,
+        kind="transcribe",
         status="queued",
         phase="chunking",
         progress=0,
+        chunk_seconds=chunk_seconds,
+        language=u.language,
+        trim_silence=bool(trim_silence),
         summarize=bool(summarize),
         generate_action_items=bool(action_items),
         llm_model=(llm_model or None),
         prompt_summary_id=prompt_summary_id,
         prompt_action_items_id=prompt_action_items_id,
+        priority=priority if priority is not None else _default_priority(size),
     )
     db.add(job)
     db.commit()
     db.refresh(job)
 
-    celery_app.send_task("worker.tasks.process_upload", args=[job.id])
+    res = celery_app.send_task("worker.tasks.process_job", args=[job.id], priority=job.priority)
+    job.celery_task_id = res.id
+    db.commit()
+    return UploadCreateResponse(upload_id=u.id, job_id=job.id)
+
+
+@router.post("/new", response_model=UploadDraftResponse)
+def create_draft_upload(req: UploadDraftRequest, db: Session = Depends(get_db)) -> UploadDraftResponse:
+    """
+    Create a conversation without audio (draft): metadata now, file later via
+    POST /{id}/audio. No job is enqueued until audio is attached.
+    """
+    profile = _resolve_profile(db, req.profile_id)
+
+    u = Upload(
+        original_filename="",
+        display_name=req.display_name.strip(),
+        stored_path="",
+        content_type=None,
+        size_bytes=None,
+        tags=None,
+        profile_id=profile.id,
+        language=profile.default_language,
+    )
+    db.add(u)
+    db.commit()
+    db.refresh(u)
+    return UploadDraftResponse(upload_id=u.id)
+
+
+@router.post("/{upload_id}/audio", response_model=UploadCreateResponse)
+def attach_audio(
+    upload_id: int,
+    file: UploadFile = File(...),
+    chunk_seconds: Optional[int] = Form(None),
+    trim_silence: bool = Form(False),
+    priority: Optional[int] = Form(None),
+    summarize: Optional[bool] = Form(None),
+    action_items: Optional[bool] = Form(None),
+    llm_model: Optional[str] = Form(None),
+    db: Session = Depends(get_db),
+) -> UploadCreateResponse:
+    """Attach audio to a draft conversation and enqueue transcription."""
+    u = _get_upload_or_404(db, upload_id)
+    if u.stored_path:
+        raise HTTPException(status_code=409, detail="Upload already has audio attached")
+    if not file.filename:
+        raise HTTPException(status_code=400, detail="Missing filename")
+    if not extension_supported(file.filename):
+        raise HTTPException(
+            status_code=400,
+            detail=f"Unsupported file type; supported extensions: {', '.join(sorted(AUDIO_FORMATS))}",
+        )
+
+    reason = _pipeline_unavailable_reason()
+    if reason:
+        raise HTTPException(
+            status_code=503,
+            detail=f"transcription not available: {reason}",
+            headers={"Retry-After": "60"},
+        )
+    if chunk_seconds is not None and not (5 <= chunk_seconds <= 300):
+        raise HTTPException(status_code=400, detail="chunk_seconds must be between 5 and 300")
+    _validate_priority(priority)
+
+    if settings.sniff_uploads:
+        head = file.file.read(512)
+        file.file.seek(0)
+        if not sniff_audio_type(head):
+            raise HTTPException(
+                status_code=400,
+                detail=f"File content is not recognized audio (magic bytes: {head[:8].hex() or 'empty'})",
+            )
+
+    _check_profile_job_cap(db, u.profile_id)
+
+    profile = db.query(Profile).filter(Profile.id == u.profile_id).first() if u.profile_id else None
+    if summarize is None:
+        summarize = bool(profile.default_summarize) if profile else False
+    if action_items is None:
+        action_items = bool(profile.default_action_items) if profile else False
+    if chunk_seconds is None and profile and profile.default_chunk_seconds:
+        chunk_seconds = profile.default_chunk_seconds
+
+    try:
+        stored_path = build_storage_path(
+            settings.upload_dir,
+            settings.upload_path_template,
+            upload_id=u.id,
+            profile_id=u.profile_id,
+            filename=file.filename,
+        )
+    except ValueError as e:
+        raise HTTPException(status_code=500, detail=str(e))
+    ensure_dir(os.path.dirname(stored_path))
+
+    size = 0
+    hasher = hashlib.sha256()
+    _INGEST_PROGRESS[u.id] = 0
+    try:
+        with open(stored_path, "wb") as out:
+            while True:
+                chunk = file.file.read(1024 * 1024)
+                if not chunk:
+                    break
+                out.write(chunk)
+                hasher.update(chunk)
+                size += len(chunk)
+                _INGEST_PROGRESS[u.id] = size
+    finally:
+        _INGEST_PROGRESS.pop(u.id, None)
+
+    u.original_filename = file.filename
+    u.content_type = file.content_type
+    u.stored_path = stored_path
+    u.size_bytes = size
+    u.content_hash = hasher.hexdigest()
+    db.commit()
+
+    job = Job(
+        upload_id=u.id,
+        kind="transcribe",
+        status="queued",
+        phase="chunking",
+        progress=0,
+        chunk_seconds=chunk_seconds,
+        language=u.language,
+        trim_silence=bool(trim_silence),
+        priority=priority if priority is not None else _default_priority(size),
+        summarize=bool(summarize),
+        generate_action_items=bool(action_items),
+        llm_model=(llm_model or None),
+    )
+    db.add(job)
+    db.commit()
+    db.refresh(job)
+
+    res = celery_app.send_task("worker.tasks.process_job", args=[job.id], priority=job.priority)
+    job.celery_task_id = res.id
+    db.commit()
+    return UploadCreateResponse(upload_id=u.id, job_id=job.id)
+
+
+@router.post("/{upload_id}/retranscribe-range")
+def retranscribe_range(upload_id: int, req: RetranscribeRangeRequest, db: Session = Depends(get_db)) -> dict:
+    u = _get_upload_or_404(db, upload_id)
+    if req.end <= req.start:
+        raise HTTPException(status_code=400, detail="end must be greater than start")
+    _require_audio_file(u)
+    _reject_if_active_job(db, upload_id)
+
+    job = Job(
+        upload_id=u.id,
+        kind="retranscribe_range",
+        status="queued",
+        phase="transcribing",
+        progress=0,
+        # Interactive correction of a short slice: jump the queue.
+        priority=_PRIORITY_INTERACTIVE,
+    )
+    db.add(job)
+    db.commit()
+    db.refresh(job)
+
+    res = celery_app.send_task(
+        "worker.tasks.process_job",
+        args=[job.id],
+        kwargs={"start": req.start, "end": req.end},
+        priority=job.priority,
+    )
+    job.celery_task_id = res.id
+    db.commit()
+    return {"upload_id": u.id, "job_id": job.id}
+
+
+@router.post("/{upload_id}/duplicate", response_model=UploadCreateResponse)
+def duplicate_upload(upload_id: int, req: UploadDuplicateRequest, db: Session = Depends(get_db)) -> UploadCreateResponse:
+    """
+    Clone an upload (copying its audio) into the same profile, optionally
+    enqueueing a fresh transcription with different options. Useful for
+    A/B testing settings on the same recording.
+    """
+    src = _get_upload_or_404(db, upload_id)
+    _require_audio_file(src)
+
+    lang = None
+    if req.language is not None:
+        lang = normalize_language(req.language)
+        if not lang:
+            raise HTTPException(status_code=400, detail=f"Unknown language code: {req.language!r}")
+
+    u = Upload(
+        original_filename=src.original_filename,
+        display_name=(req.display_name or f"{src.display_name} (copy)").strip(),
+        stored_path="",
+        content_type=src.content_type,
+        size_bytes=src.size_bytes,
+        content_hash=src.content_hash,
+        tags=src.tags,
+        profile_id=src.profile_id,
+        language=lang or src.language,
+    )
+    db.add(u)
+    db.commit()
+    db.refresh(u)
+
+    try:
+        stored_path = build_storage_path(
+            settings.upload_dir,
+            settings.upload_path_template,
+            upload_id=u.id,
+            profile_id=u.profile_id,
+            filename=src.original_filename,
+        )
+    except ValueError as e:
+        raise HTTPException(status_code=500, detail=str(e))
+    ensure_dir(os.path.dirname(stored_path))
+    shutil.copyfile(src.stored_path, stored_path)
+    u.stored_path = stored_path
+    db.commit()
+
+    if not req.transcribe:
+        return UploadCreateResponse(upload_id=u.id, job_id=None)
+
+    job = Job(
+        upload_id=u.id,
+        kind="transcribe",
+        status="queued",
+        phase="chunking",
+        progress=0,
+        chunk_seconds=req.chunk_seconds,
+        language=lang,
+        trim_silence=bool(req.trim_silence),
+        priority=_default_priority(u.size_bytes),
+        summarize=bool(req.summarize),
+        generate_action_items=bool(req.action_items),
+        llm_model=(req.llm_model or None),
+    )
+    db.add(job)
+    db.commit()
+    db.refresh(job)
+
+    res = celery_app.send_task("worker.tasks.process_job", args=[job.id], priority=job.priority)
+    job.celery_task_id = res.id
+    db.commit()
     return UploadCreateResponse(upload_id=u.id, job_id=job.id)
 
 
 @router.post("/{upload_id}/reprocess", response_model=UploadReprocessResponse)
 def reprocess_upload(upload_id: int, req: UploadReprocessRequest, db: Session = Depends(get_db)) -> UploadReprocessResponse:
-    u = db.query(Upload).filter(Upload.id == upload_id).first()
-    if not u:
-        raise HTTPException(status_code=404, detail="Upload not found")
+    u = _get_upload_or_404(db, upload_id)
 
     if not req.summarize and not req.action_items:
         raise HTTPException(status_code=400, detail="Select summarize and/or action_items")
+    _reject_if_active_job(db, upload_id)
 
     job = Job(
         upload_id=u.id,
+        kind="llm",
         status="queued",
         phase="summarizing" if req.summarize else "action_items",
         progress=0,
@@ -273,6 +1478,9 @@ def reprocess_upload(upload_id: int, req: UploadReprocessRequest, db: Session =
     db.commit()
     db.refresh(job)
 
-    celery_app.send_task("worker.tasks.process_llm", args=[job.id])
-    return UploadReprocessResponse(upload_id=u.id, job_id=job.id)
+    res = celery_app.send_task("worker.tasks.process_job", args=[job.id], priority=job.priority)
+    job.celery_task_id = res.id
+    db.commit()
+    steps = [s for s, on in (("summary", req.summarize), ("action_items", req.action_items)) if on]
+    return UploadReprocessResponse(upload_id=u.id, job_id=job.id, steps=steps)
 
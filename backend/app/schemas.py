@@ -3,7 +3,26 @@ from __future__ import annotations
 from datetime import datetime
 from typing import Any, Optional
 
-from pydantic import BaseModel, Field
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class ProfileOut(BaseModel):
+    id: int
+    name: str
+    is_active: bool
+    default_language: Optional[str] = None
+    default_summarize: Optional[bool] = None
+    default_action_items: Optional[bool] = None
+    default_chunk_seconds: Optional[int] = None
+    created_at: datetime
+
+
+class ProfileUpdateRequest(BaseModel):
+    name: Optional[str] = Field(None, min_length=1, max_length=100)
+    default_language: Optional[str] = Field(None, min_length=2, max_length=10)
+    default_summarize: Optional[bool] = None
+    default_action_items: Optional[bool] = None
+    default_chunk_seconds: Optional[int] = Field(None, ge=5, le=300)
 
 
 class UploadListItem(BaseModel):
@@ -14,11 +33,38 @@ class UploadListItem(BaseModel):
     duration_seconds: Optional[float] = None
     language: Optional[str] = None
     tags: list[str] = Field(default_factory=list)
+    is_favorite: bool = False
+    # Latest job status, populated only with ?with_status=true on the list
+    job_status: Optional[str] = None
 
 
 class UploadCreateResponse(BaseModel):
     upload_id: int
-    job_id: int
+    job_id: Optional[int] = None
+    # True when dedupe matched an existing upload and no new job was created
+    duplicate: bool = False
+
+
+class UploadDraftRequest(BaseModel):
+    display_name: str = Field(min_length=1, max_length=200)
+    profile_id: Optional[int] = None
+
+
+class UploadDraftResponse(BaseModel):
+    upload_id: int
+
+
+class UploadDuplicateRequest(BaseModel):
+    display_name: Optional[str] = Field(None, min_length=1, max_length=200)
+    # When true, a fresh transcribe job runs on the copy with the options
+    # below; omitted options fall back to the usual defaults.
+    transcribe: bool = True
+    chunk_seconds: Optional[int] = Field(None, ge=5, le=300)
+    trim_silence: bool = False
+    language: Optional[str] = None
+    summarize: bool = False
+    action_items: bool = False
+    llm_model: Optional[str] = None
 
 
 class UploadReprocessRequest(BaseModel):
@@ -30,6 +76,25 @@ class UploadReprocessRequest(BaseModel):
 class UploadReprocessResponse(BaseModel):
     upload_id: int
     job_id: int
+    steps: list[str] = Field(default_factory=list)
+
+
+class JobStatus(BaseModel):
+    id: int
+    upload_id: int
+    kind: str = "transcribe"
+    status: str
+    phase: Optional[str] = None
+    progress: int = 0
+    total_chunks: Optional[int] = None
+    current_chunk: Optional[int] = None
+    error: Optional[str] = None
+    message: Optional[str] = None
+    # Options the run was started with, so failed runs can be reproduced.
+    options: dict[str, Any] = Field(default_factory=dict)
+    created_at: datetime
+    started_at: Optional[datetime] = None
+    finished_at: Optional[datetime] = None
 
 
 class UploadDetail(BaseModel):
@@ -38,31 +103,41 @@ class UploadDetail(BaseModel):
     original_filename: str
     created_at: datetime
     duration_seconds: Optional[float] = None
+    # Silence trimmed off either end before transcription. Segment times are
+    # relative to the trimmed audio while playback serves the original file,
+    # so clients add trim_start_seconds to line segments up with the player.
+    trim_start_seconds: Optional[float] = None
+    trim_end_seconds: Optional[float] = None
     language: Optional[str] = None
     tags: list[str] = Field(default_factory=list)
+    is_favorite: bool = False
+    keywords: list[str] = Field(default_factory=list)
+    notes: Optional[str] = None
     summary: Optional[str] = None
     action_items: Optional[Any] = None
 
     transcript_text: Optional[str] = None
+    transcript_status: Optional[str] = None
+
+    # Latest job, embedded only when requested with ?include_job=true.
+    job: Optional[JobStatus] = None
 
 
 class UploadUpdateRequest(BaseModel):
     display_name: Optional[str] = Field(None, min_length=1, max_length=200)
     tags: Optional[list[str]] = None
+    notes: Optional[str] = Field(None, max_length=10000)
 
 
-class JobStatus(BaseModel):
+class ActivityItem(BaseModel):
     id: int
-    upload_id: int
-    status: str
-    phase: Optional[str] = None
-    progress: int = 0
-    total_chunks: Optional[int] = None
-    current_chunk: Optional[int] = None
-    error: Optional[str] = None
-    created_at: datetime
-    started_at: Optional[datetime] = None
-    finished_at: Optional[datetime] = None
+    display_name: str
+    updated_at: datetime
+    duration_seconds: Optional[float] = None
+    job_id: Optional[int] = None
+    job_status: Optional[str] = None
+    job_phase: Optional[str] = None
+    job_progress: Optional[int] = None
 
 
 class JobStats(BaseModel):
@@ -71,11 +146,80 @@ class JobStats(BaseModel):
     active: int
 
 
+class TranscriptImportSegment(BaseModel):
+    start: float = Field(ge=0)
+    end: float = Field(gt=0)
+    text: str
+
+
+class TranscriptImportRequest(BaseModel):
+    # Provide exactly one of: structured segments, or raw SRT/VTT content.
+    segments: Optional[list[TranscriptImportSegment]] = None
+    subtitles: Optional[str] = None
+
+
+class RetranscribeRangeRequest(BaseModel):
+    start: float = Field(ge=0)
+    end: float = Field(gt=0)
+
+
+class LanguageUpdateRequest(BaseModel):
+    language: str = Field(min_length=2, max_length=10)
+
+
+class TranscriptVersionOut(BaseModel):
+    id: int
+    version: int
+    source: str
+    text_length: int
+    created_at: datetime
+
+
 class TranscriptSegmentOut(BaseModel):
     id: int
     start_time: float
     end_time: float
     text: str
+    kind: str = "speech"
+    confidence: Optional[float] = None
+
+
+class TranscriptFullOut(BaseModel):
+    upload_id: int
+    text: str
+    status: str
+    language: Optional[str] = None
+    # Quality summary over segments with a confidence value; None when no
+    # segment carries one (imports, pre-upgrade transcripts).
+    avg_confidence: Optional[float] = None
+    low_confidence_ratio: Optional[float] = None
+    segments: list[TranscriptSegmentOut] = Field(default_factory=list)
+
+
+class TranscriptUpdateRequest(BaseModel):
+    # Unknown fields are rejected (422) rather than silently dropped, so a
+    # typo'd field name in a PATCH doesn't look like a successful update.
+    model_config = ConfigDict(extra="forbid")
+
+    duration_seconds: Optional[float] = Field(None, ge=0)
+    language: Optional[str] = None
+    summary: Optional[str] = None
+
+
+class TranscriptTurnOut(BaseModel):
+    speaker: Optional[str] = None
+    start_time: float
+    end_time: float
+    text: str
+
+
+class ChapterOut(BaseModel):
+    start_time: float
+    end_time: float
+    label: str
+    # Estimated silent-reading time for the chapter's speech text, which is
+    # usually much shorter than end_time - start_time.
+    reading_time_seconds: float = 0.0
 
 
 class PromptOut(BaseModel):
@@ -0,0 +1,33 @@
+from __future__ import annotations
+
+from typing import Any
+
+from fastapi.responses import JSONResponse
+
+
+def to_camel(name: str) -> str:
+    parts = name.split("_")
+    return parts[0] + "".join(p.title() for p in parts[1:])
+
+
+def camelize_keys(value: Any) -> Any:
+    """Recursively convert dict keys from snake_case to camelCase."""
+    if isinstance(value, dict):
+        return {
+            (to_camel(k) if isinstance(k, str) else k): camelize_keys(v)
+            for k, v in value.items()
+        }
+    if isinstance(value, list):
+        return [camelize_keys(v) for v in value]
+    return value
+
+
+class CamelCaseJSONResponse(JSONResponse):
+    """
+    Response class used when API_FIELD_CASE=camel. Re-cases keys at the
+    encoding boundary only, so route handlers and schemas keep their
+    snake_case field names.
+    """
+
+    def render(self, content: Any) -> bytes:
+        return super().render(camelize_keys(content))
@@ -0,0 +1,105 @@
+from __future__ import annotations
+
+import json
+import logging
+import os
+from datetime import datetime
+
+from sqlalchemy.orm import Session
+
+from app.config import settings
+from app.models import Transcript, TranscriptSegment, Upload
+
+logger = logging.getLogger(__name__)
+
+
+def archive_enabled() -> bool:
+    return bool(settings.archive_s3_bucket)
+
+
+def _client():
+    # boto3 is an optional dependency; only deployments that archive need it.
+    try:
+        import boto3
+    except ImportError as exc:
+        raise RuntimeError("boto3 is not installed; S3 archival requires it") from exc
+
+    kwargs: dict = {}
+    if settings.archive_s3_endpoint_url:
+        kwargs["endpoint_url"] = settings.archive_s3_endpoint_url
+    if settings.archive_s3_region:
+        kwargs["region_name"] = settings.archive_s3_region
+    if settings.archive_s3_access_key_id and settings.archive_s3_secret_access_key:
+        kwargs["aws_access_key_id"] = settings.archive_s3_access_key_id
+        kwargs["aws_secret_access_key"] = settings.archive_s3_secret_access_key
+    return boto3.client("s3", **kwargs)
+
+
+def _transcript_document(db: Session, upload: Upload) -> dict:
+    tr = db.query(Transcript).filter(Transcript.upload_id == upload.id).first()
+    segments = (
+        db.query(TranscriptSegment)
+        .filter(TranscriptSegment.upload_id == upload.id)
+        .order_by(TranscriptSegment.start_time.asc())
+        .all()
+    )
+    return {
+        "upload_id": upload.id,
+        "display_name": upload.display_name,
+        "language": upload.language,
+        "duration_seconds": upload.duration_seconds,
+        "text": tr.text if tr else "",
+        "summary": upload.summary,
+        "action_items": upload.action_items,
+        "segments": [
+            {"start": s.start_time, "end": s.end_time, "text": s.text, "kind": s.kind}
+            for s in segments
+        ],
+    }
+
+
+def archive_upload(db: Session, upload: Upload) -> dict:
+    """
+    Push the upload's audio file and a transcript JSON document to the
+    configured bucket, recording the object keys on the upload row.
+
+    Raises RuntimeError when archival isn't usable (unconfigured or boto3
+    missing); callers on the job path should catch and log, never fail the job.
+    """
+    if not archive_enabled():
+        raise RuntimeError("archival is not configured (ARCHIVE_S3_BUCKET unset)")
+
+    client = _client()
+    bucket = settings.archive_s3_bucket
+    prefix = settings.archive_s3_prefix.strip("/")
+
+    ext = os.path.splitext(upload.stored_path or "")[1]
+    audio_key = f"{prefix}/{upload.id}/audio{ext}"
+    transcript_key = f"{prefix}/{upload.id}/transcript.json"
+
+    if upload.stored_path and os.path.exists(upload.stored_path):
+        client.upload_file(upload.stored_path, bucket, audio_key)
+    else:
+        audio_key = None
+
+    doc = _transcript_document(db, upload)
+    client.put_object(
+        Bucket=bucket,
+        Key=transcript_key,
+        Body=json.dumps(doc, ensure_ascii=False).encode("utf-8"),
+        ContentType="application/json",
+    )
+
+    upload.archive_audio_key = audio_key
+    upload.archive_transcript_key = transcript_key
+    upload.archived_at = datetime.utcnow()
+    db.commit()
+
+    logger.info("Archived upload %s to s3://%s/%s/%s", upload.id, bucket, prefix, upload.id)
+    return {
+        "upload_id": upload.id,
+        "bucket": bucket,
+        "audio_key": audio_key,
+        "transcript_key": transcript_key,
+        "archived_at": upload.archived_at.isoformat(),
+    }
@@ -0,0 +1,88 @@
+from __future__ import annotations
+
+import os
+import threading
+import uuid
+
+from app.services.commands import run_command
+
+# Playback formats we can transcode to on demand: fmt -> (ffmpeg codec, MIME).
+# mp3 covers the browsers that can't play flac/wma natively.
+_TRANSCODE_FORMATS = {
+    "mp3": ("libmp3lame", "audio/mpeg"),
+}
+
+
+def transcode_supported(fmt: str) -> bool:
+    return fmt in _TRANSCODE_FORMATS
+
+
+# One lock per cache file so concurrent first requests for the same upload
+# run a single ffmpeg instead of racing each other; different uploads still
+# transcode in parallel. The guard protects the dict itself.
+_TRANSCODE_LOCKS: dict[str, threading.Lock] = {}
+_TRANSCODE_LOCKS_GUARD = threading.Lock()
+
+
+def _transcode_lock(cache_path: str) -> threading.Lock:
+    with _TRANSCODE_LOCKS_GUARD:
+        return _TRANSCODE_LOCKS.setdefault(cache_path, threading.Lock())
+
+
+def cached_transcode(input_path: str, cache_dir: str, fmt: str) -> tuple[str, str]:
+    """
+    Transcode the audio once per format and reuse the cached file. Returns
+    (path, mime_type). The first request for a format blocks until the full
+    transcode finishes (bounded by COMMAND_TIMEOUT_SECONDS) — this is a
+    cache fill, not streaming — and writes via a unique temp name + rename
+    so a concurrent request never sees a partial file.
+    """
+    if fmt not in _TRANSCODE_FORMATS:
+        raise ValueError(f"unsupported playback format: {fmt}")
+    codec, mime = _TRANSCODE_FORMATS[fmt]
+
+    cache_path = os.path.join(cache_dir, f"playback.{fmt}")
+    if os.path.exists(cache_path):
+        return cache_path, mime
+
+    with _transcode_lock(cache_path):
+        return _fill_transcode_cache(input_path, cache_path, codec, mime)
+
+
+def _fill_transcode_cache(input_path: str, cache_path: str, codec: str, mime: str) -> tuple[str, str]:
+    # Re-check under the lock: another thread may have just finished.
+    if os.path.exists(cache_path):
+        return cache_path, mime
+
+    cache_dir = os.path.dirname(cache_path)
+    os.makedirs(cache_dir, exist_ok=True)
+    tmp_path = f"{cache_path}.tmp-{uuid.uuid4().hex}"
+    try:
+        returncode, _, stderr = run_command(
+            [
+                "ffmpeg",
+                "-hide_banner",
+                "-loglevel", "error",
+                "-y",
+                "-i", input_path,
+                "-vn",
+                "-codec:a", codec,
+                "-q:a", "4",
+                tmp_path,
+            ],
+            text=True,
+        )
+    except RuntimeError:
+        try:
+            os.remove(tmp_path)
+        except OSError:
+            pass
+        raise
+    if returncode != 0:
+        try:
+            os.remove(tmp_path)
+        except OSError:
+            pass
+        raise RuntimeError(f"ffmpeg transcode failed: {(stderr or '').strip()}")
+    os.replace(tmp_path, cache_path)
+    return cache_path, mime
@@ -0,0 +1,33 @@
+from __future__ import annotations
+
+import os
+import signal
+import subprocess
+
+from app.config import settings
+
+
+def run_command(cmd: list[str], *, capture_stdout: bool = False, text: bool = False):
+    """
+    Run an external command (ffmpeg/ffprobe) under COMMAND_TIMEOUT_SECONDS,
+    SIGKILLing the whole process group on expiry — the same contract as the
+    worker pipeline's _run. Returns (returncode, stdout, stderr); stdout is
+    None unless capture_stdout is set.
+    """
+    p = subprocess.Popen(
+        cmd,
+        stdout=subprocess.PIPE if capture_stdout else subprocess.DEVNULL,
+        stderr=subprocess.PIPE,
+        text=text,
+        start_new_session=True,
+    )
+    try:
+        stdout, stderr = p.communicate(timeout=settings.command_timeout_seconds)
+    except subprocess.TimeoutExpired:
+        try:
+            os.killpg(os.getpgid(p.pid), signal.SIGKILL)
+        except (ProcessLookupError, PermissionError):
+            pass
+        p.wait()
+        raise RuntimeError(f"command timed out after {settings.command_timeout_seconds}s: {' '.join(cmd)}")
+    return p.returncode, stdout, stderr
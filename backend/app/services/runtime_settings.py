@@ -0,0 +1,32 @@
+from __future__ import annotations
+
+from sqlalchemy.orm import Session
+
+from app.models import Setting
+
+# The API and worker are separate processes whose only shared state is the
+# DB, so runtime toggles like queue pausing live in the settings table.
+QUEUE_PAUSED_KEY = "queue_paused"
+
+
+def get_setting(db: Session, key: str) -> str | None:
+    row = db.query(Setting).filter(Setting.key == key).first()
+    return row.value if row else None
+
+
+def set_setting(db: Session, key: str, value: str) -> None:
+    """Upsert a setting (commits)."""
+    row = db.query(Setting).filter(Setting.key == key).first()
+    if row:
+        row.value = value
+    else:
+        db.add(Setting(key=key, value=value))
+    db.commit()
+
+
+def queue_paused(db: Session) -> bool:
+    return get_setting(db, QUEUE_PAUSED_KEY) == "1"
+
+
+def set_queue_paused(db: Session, paused: bool) -> None:
+    set_setting(db, QUEUE_PAUSED_KEY, "1" if paused else "0")
@@ -17,6 +17,78 @@ def safe_filename(name: str) -> str:
     return f"{uuid.uuid4().hex}{ext}"
 
 
+def derive_display_name(filename: str) -> str:
+    """
+    Turn a raw filename into a readable default title: drop the extension,
+    treat underscores/dashes as word separators, and collapse whitespace
+    ("team_sync-2024-03-01.mp3" -> "team sync 2024 03 01"). Returns "" for
+    names with nothing left, so callers can fall back to the raw filename.
+    """
+    stem = pathlib.Path(filename or "").stem
+    return " ".join(stem.replace("_", " ").replace("-", " ").split())
+
+
+def build_storage_path(
+    root: str,
+    template: str,
+    *,
+    upload_id: int,
+    profile_id: int | None,
+    filename: str,
+) -> str:
+    """
+    Resolve the stored-file path from the configured template. Supported
+    placeholders: {upload}, {profile}, {name} (randomized safe filename),
+    {ext} (lowercase extension without dot). The result must stay inside
+    the upload root.
+    """
+    name = safe_filename(filename)
+    ext = pathlib.Path(filename).suffix.lower().lstrip(".")
+    try:
+        rel = template.format(upload=upload_id, profile=profile_id or 0, name=name, ext=ext)
+    except (KeyError, IndexError) as e:
+        raise ValueError(f"invalid upload_path_template: {e}")
+
+    root_abs = os.path.normpath(os.path.abspath(root))
+    path = os.path.normpath(os.path.join(root_abs, rel))
+    if not path.startswith(root_abs + os.sep):
+        raise ValueError("upload_path_template escapes the upload root")
+    return path
+
+
+def sniff_audio_type(head: bytes) -> str | None:
+    """
+    Best-effort audio detection from magic bytes (first ~512 bytes).
+    Returns a MIME type when the content looks like audio, else None.
+    Extension checks alone are trivially spoofed.
+    """
+    if len(head) < 4:
+        return None
+    if head[:4] == b"RIFF" and head[8:12] == b"WAVE":
+        return "audio/wav"
+    if head[:3] == b"ID3":
+        return "audio/mpeg"
+    # ADTS AAC frame sync (must precede the generic MPEG sync check below)
+    if head[0] == 0xFF and head[1] in (0xF1, 0xF9):
+        return "audio/aac"
+    # Raw MPEG audio frame sync (0xFFEx / 0xFFFx)
+    if head[0] == 0xFF and (head[1] & 0xE0) == 0xE0:
+        return "audio/mpeg"
+    if head[4:8] == b"ftyp":
+        return "audio/mp4"
+    if head[:4] == b"OggS":
+        return "audio/ogg"
+    if head[:4] == b"fLaC":
+        return "audio/flac"
+    # ASF container (WMA) header GUID
+    if head[:4] == b"\x30\x26\xb2\x75":
+        return "audio/x-ms-wma"
+    # WebM/Matroska (EBML header) - audio-only webm is common for recordings
+    if head[:4] == b"\x1a\x45\xdf\xa3":
+        return "audio/webm"
+    return None
+
+
 def delete_tree(path: str) -> None:
     if not path:
         return
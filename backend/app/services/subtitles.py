@@ -0,0 +1,49 @@
+from __future__ import annotations
+
+import re
+
+_TIMESTAMP = re.compile(
+    r"(?:(\d+):)?(\d{1,2}):(\d{2})[.,](\d{1,3})"
+)
+
+
+def _parse_timestamp(raw: str) -> float | None:
+    m = _TIMESTAMP.fullmatch(raw.strip())
+    if not m:
+        return None
+    hours = int(m.group(1) or 0)
+    minutes = int(m.group(2))
+    seconds = int(m.group(3))
+    millis = int(m.group(4).ljust(3, "0"))
+    return hours * 3600 + minutes * 60 + seconds + millis / 1000.0
+
+
+def parse_subtitles(content: str) -> list[tuple[float, float, str]]:
+    """
+    Parse SRT or WebVTT content into (start, end, text) tuples. The two
+    formats are close enough that one cue-oriented parser covers both:
+    we look for "start --> end" lines and collect the text below them.
+    """
+    cues: list[tuple[float, float, str]] = []
+    lines = content.replace("\r\n", "\n").replace("\r", "\n").split("\n")
+
+    i = 0
+    while i < len(lines):
+        line = lines[i].strip()
+        if "-->" in line:
+            left, _, right = line.partition("-->")
+            # VTT cue timings may carry settings after the end timestamp.
+            start = _parse_timestamp(left)
+            end = _parse_timestamp(right.strip().split(" ")[0])
+            if start is not None and end is not None and end > start:
+                text_lines: list[str] = []
+                i += 1
+                while i < len(lines) and lines[i].strip():
+                    text_lines.append(lines[i].strip())
+                    i += 1
+                text = " ".join(text_lines).strip()
+                if text:
+                    cues.append((start, end, text))
+                continue
+        i += 1
+    return cues
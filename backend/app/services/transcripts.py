@@ -0,0 +1,28 @@
+from __future__ import annotations
+
+from sqlalchemy import func
+from sqlalchemy.orm import Session
+
+from app.models import TranscriptVersion
+
+
+def record_transcript_version(db: Session, upload_id: int, text: str, source: str) -> TranscriptVersion:
+    """
+    Snapshot transcript text for an upload. Version numbers start at 1 (the
+    initial machine transcript) and increase monotonically per upload.
+
+    Does not commit; callers own the transaction.
+    """
+    latest = (
+        db.query(func.max(TranscriptVersion.version))
+        .filter(TranscriptVersion.upload_id == upload_id)
+        .scalar()
+    )
+    v = TranscriptVersion(
+        upload_id=upload_id,
+        version=(latest or 0) + 1,
+        text=text,
+        source=source,
+    )
+    db.add(v)
+    return v
@@ -0,0 +1,77 @@
+from __future__ import annotations
+
+import json
+import os
+
+from app.services.commands import run_command
+
+# Decode rate for peak extraction. Low on purpose: the waveform is visual,
+# not analytical, and 8 kHz mono keeps decode time small for long files.
+_DECODE_RATE = 8000
+
+
+def compute_waveform_peaks(input_path: str, buckets: int) -> list[float]:
+    """
+    Decode the audio to mono PCM with ffmpeg and reduce it to `buckets`
+    normalized peak values (0..1) for rendering a waveform.
+    """
+    returncode, raw, stderr = run_command(
+        [
+            "ffmpeg",
+            "-hide_banner",
+            "-loglevel", "error",
+            "-i", input_path,
+            "-ac", "1",
+            "-ar", str(_DECODE_RATE),
+            "-f", "s16le",
+            "-",
+        ],
+        capture_stdout=True,
+    )
+    if returncode != 0:
+        raise RuntimeError(f"ffmpeg decode failed: {stderr.decode('utf-8', 'replace').strip()}")
+
+    n_samples = len(raw) // 2
+    if n_samples == 0:
+        return [0.0] * buckets
+
+    import array
+
+    samples = array.array("h")
+    samples.frombytes(raw[: n_samples * 2])
+
+    per_bucket = max(1, n_samples // buckets)
+    peaks: list[float] = []
+    for i in range(buckets):
+        lo = i * per_bucket
+        if lo >= n_samples:
+            peaks.append(0.0)
+            continue
+        hi = min(n_samples, lo + per_bucket)
+        peak = max(abs(s) for s in samples[lo:hi])
+        peaks.append(peak / 32768.0)
+
+    top = max(peaks)
+    if top > 0:
+        peaks = [round(v / top, 4) for v in peaks]
+    return peaks
+
+
+def cached_waveform(input_path: str, cache_dir: str, buckets: int) -> list[float]:
+    """Compute peaks once per (upload, bucket count) and reuse the JSON file."""
+    cache_path = os.path.join(cache_dir, f"waveform-{buckets}.json")
+    if os.path.exists(cache_path):
+        try:
+            with open(cache_path, "r", encoding="utf-8") as f:
+                return json.load(f)
+        except (OSError, ValueError):
+            pass  # corrupt/unreadable cache: recompute below
+
+    peaks = compute_waveform_peaks(input_path, buckets)
+    os.makedirs(cache_dir, exist_ok=True)
+    try:
+        with open(cache_path, "w", encoding="utf-8") as f:
+            json.dump(peaks, f)
+    except OSError:
+        pass
+    return peaks
@@ -0,0 +1,72 @@
+from __future__ import annotations
+
+import hashlib
+import hmac
+import json
+import logging
+import time
+from typing import Optional
+
+import httpx
+
+from app.config import settings
+
+logger = logging.getLogger(__name__)
+
+_MAX_ATTEMPTS = 3
+_TIMEOUT_S = 10.0
+
+
+def notify_job_finished(
+    *,
+    upload_id: int,
+    job_id: int,
+    status: str,
+    transcript_id: Optional[int] = None,
+    error: Optional[str] = None,
+) -> None:
+    """
+    POST a job-finished event to WEBHOOK_URL, if configured.
+
+    Delivery is best-effort: failures are logged and never propagate to the
+    caller, so a broken receiver can't fail jobs. When WEBHOOK_SECRET is set,
+    the body is signed with HMAC-SHA256 and sent as X-Listen-Signature.
+    """
+    url = settings.webhook_url
+    if not url:
+        return
+
+    body = json.dumps(
+        {
+            "event": "job.finished",
+            "upload_id": upload_id,
+            "job_id": job_id,
+            "status": status,
+            "transcript_id": transcript_id,
+            "error": error,
+        }
+    ).encode("utf-8")
+
+    headers = {"Content-Type": "application/json"}
+    if settings.webhook_secret:
+        sig = hmac.new(settings.webhook_secret.encode("utf-8"), body, hashlib.sha256).hexdigest()
+        headers["X-Listen-Signature"] = f"sha256={sig}"
+
+    for attempt in range(1, _MAX_ATTEMPTS + 1):
+        try:
+            resp = httpx.post(url, content=body, headers=headers, timeout=_TIMEOUT_S)
+            if resp.status_code < 300:
+                return
+            logger.warning(
+                "webhook for job %s returned %s (attempt %s/%s)",
+                job_id, resp.status_code, attempt, _MAX_ATTEMPTS,
+            )
+        except Exception as exc:
+            logger.warning(
+                "webhook for job %s failed: %s (attempt %s/%s)",
+                job_id, exc, attempt, _MAX_ATTEMPTS,
+            )
+        if attempt < _MAX_ATTEMPTS:
+            time.sleep(2 ** attempt)
+
+    logger.error("giving up on webhook for job %s after %s attempts", job_id, _MAX_ATTEMPTS)
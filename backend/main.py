@@ -1,5 +1,33 @@
+from __future__ import annotations
+
+import logging
+
+import uvicorn
+
+from app.config import settings
+
+logger = logging.getLogger("listen")
+
+
 def main():
-    print("Hello from backend!")
+    ssl_kwargs: dict = {}
+    if settings.tls_cert_file and settings.tls_key_file:
+        ssl_kwargs = {
+            "ssl_certfile": settings.tls_cert_file,
+            "ssl_keyfile": settings.tls_key_file,
+        }
+        logger.info("Starting HTTPS server on %s:%s", settings.api_host, settings.api_port)
+    else:
+        logger.info("Starting HTTP server on %s:%s", settings.api_host, settings.api_port)
+
+    uvicorn.run(
+        "app.main:app",
+        host=settings.api_host,
+        port=settings.api_port,
+        timeout_keep_alive=settings.server_keepalive_timeout,
+        timeout_graceful_shutdown=settings.server_graceful_shutdown_timeout,
+        **ssl_kwargs,
+    )
 
 
 if __name__ == "__main__":
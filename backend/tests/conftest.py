@@ -0,0 +1,67 @@
+from __future__ import annotations
+
+import os
+import sys
+import tempfile
+
+# Make the backend packages importable regardless of where pytest is invoked.
+sys.path.insert(0, os.path.dirname(os.path.dirname(os.path.abspath(__file__))))
+
+# Test configuration must be in place before any app module is imported:
+# app.config builds the Settings singleton at import time, and app.db builds
+# the engine from it. "sqlite://" selects the shared in-memory DB (StaticPool
+# pins it to one connection so the schema survives between requests).
+os.environ["DATABASE_URL"] = "sqlite://"
+os.environ["DATABASE_READ_URL"] = ""
+os.environ["UPLOAD_DIR"] = tempfile.mkdtemp(prefix="listen-test-uploads-")
+os.environ["ADMIN_API_KEY"] = ""
+os.environ["STRICT_STARTUP"] = "false"
+os.environ["WARMUP_MODEL"] = "false"
+os.environ["WEBHOOK_URL"] = ""
+
+import pytest
+from fastapi.testclient import TestClient
+
+
+@pytest.fixture(autouse=True)
+def broker_stub(monkeypatch):
+    """
+    Keep tests off RabbitMQ: enqueues and revokes are recorded instead of
+    sent. Yields the record list so tests can assert on queue traffic.
+    """
+    from worker.celery_app import celery_app
+
+    calls: list[tuple] = []
+
+    class _FakeTask:
+        id = "test-task-id"
+
+    def fake_send_task(*args, **kwargs):
+        calls.append(("send_task", args, kwargs))
+        return _FakeTask()
+
+    def fake_revoke(*args, **kwargs):
+        calls.append(("revoke", args, kwargs))
+
+    monkeypatch.setattr(celery_app, "send_task", fake_send_task)
+    monkeypatch.setattr(celery_app.control, "revoke", fake_revoke)
+    yield calls
+
+
+@pytest.fixture(scope="session")
+def client():
+    from app.main import app
+
+    with TestClient(app) as c:
+        yield c
+
+
+@pytest.fixture()
+def db_session():
+    from app.db import SessionLocal
+
+    db = SessionLocal()
+    try:
+        yield db
+    finally:
+        db.close()
@@ -0,0 +1,30 @@
+from __future__ import annotations
+
+from sqlalchemy import inspect
+
+from app.db import engine, ensure_schema, sqlite_file_path
+
+
+def test_in_memory_schema_created_and_idempotent():
+    # ensure_schema ran at app import; running it again must be a no-op
+    # rather than failing on existing tables/columns (fresh and existing
+    # DBs share the same code path).
+    ensure_schema()
+    ensure_schema()
+    tables = set(inspect(engine).get_table_names())
+    assert {"uploads", "jobs", "profiles", "transcripts", "transcript_segments"} <= tables
+
+
+def test_migrated_columns_present():
+    cols = {c["name"] for c in inspect(engine).get_columns("jobs")}
+    assert {"priority", "chunk_state", "celery_task_id"} <= cols
+    seg_cols = {c["name"] for c in inspect(engine).get_columns("transcript_segments")}
+    assert "confidence" in seg_cols
+
+
+def test_sqlite_file_path_parsing():
+    assert sqlite_file_path("sqlite:////data/listen.db") == "/data/listen.db"
+    assert sqlite_file_path("sqlite:///file:/data/listen.db?mode=ro&uri=true") == "/data/listen.db"
+    assert sqlite_file_path("sqlite://") is None
+    assert sqlite_file_path("sqlite:///:memory:") is None
+    assert sqlite_file_path("postgresql://x/y") is None
@@ -0,0 +1,38 @@
+from __future__ import annotations
+
+import asyncio
+
+from fastapi.testclient import TestClient
+
+
+def test_request_id_header_set_and_echoed(client):
+    r = client.get("/healthz")
+    assert r.headers["x-request-id"]
+
+    r = client.get("/healthz", headers={"X-Request-ID": "abc123"})
+    assert r.headers["x-request-id"] == "abc123"
+
+
+def test_error_body_carries_request_id(client):
+    r = client.get("/api/uploads/999999")
+    assert r.status_code == 404
+    # Error bodies carry the request id and it matches the response header.
+    assert r.json()["request_id"] == r.headers["x-request-id"]
+
+
+def test_request_timeout_returns_504(monkeypatch, broker_stub):
+    from app.config import settings
+    from app.main import create_app
+
+    monkeypatch.setattr(settings, "request_timeout_seconds", 1)
+    app = create_app()
+
+    @app.get("/api/slow-test-endpoint")
+    async def slow():
+        await asyncio.sleep(2)
+        return {"ok": True}
+
+    with TestClient(app) as c:
+        r = c.get("/api/slow-test-endpoint")
+        assert r.status_code == 504
+        assert r.json()["detail"] == "Request timed out"
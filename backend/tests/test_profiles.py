@@ -0,0 +1,55 @@
+from __future__ import annotations
+
+from app.models import Profile
+from app.routes.profiles import ensure_default_profile
+
+
+def test_fresh_db_has_a_profile(client):
+    # First-run bootstrap: the app must come up with at least one active
+    # profile so the UI always has something to upload into.
+    r = client.get("/api/profiles")
+    assert r.status_code == 200
+    profiles = r.json()
+    assert profiles, "expected the startup bootstrap to create a profile"
+    assert any(p["is_active"] for p in profiles)
+
+
+def test_ensure_default_profile_idempotent(client, db_session):
+    before = db_session.query(Profile).count()
+    first = ensure_default_profile(db_session)
+    second = ensure_default_profile(db_session)
+    assert first.id == second.id
+    assert db_session.query(Profile).count() == before
+
+
+def test_all_inactive_profiles_are_not_refabricated(client, db_session):
+    # Deactivating every profile is a deliberate state; the bootstrap must
+    # not quietly manufacture a replacement on each listing.
+    db_session.query(Profile).update({"is_active": False})
+    db_session.commit()
+    try:
+        before = db_session.query(Profile).count()
+        assert ensure_default_profile(db_session) is None
+
+        r = client.get("/api/profiles")
+        assert r.status_code == 200
+        assert r.json() == []
+        assert db_session.query(Profile).count() == before
+    finally:
+        db_session.query(Profile).update({"is_active": True})
+        db_session.commit()
+
+
+def test_bootstrap_disabled_with_flag_off(monkeypatch, client, db_session):
+    from app.config import settings
+
+    monkeypatch.setattr(settings, "auto_create_default_profile", False)
+    db_session.query(Profile).delete()
+    db_session.commit()
+
+    # With the flag off, listing an empty DB stays empty instead of
+    # creating a profile behind the operator's back.
+    r = client.get("/api/profiles")
+    assert r.status_code == 200
+    assert r.json() == []
+    assert db_session.query(Profile).count() == 0
@@ -0,0 +1,31 @@
+from __future__ import annotations
+
+import pytest
+
+from app.services.storage import build_storage_path, derive_display_name, sniff_audio_type
+
+
+def test_derive_display_name():
+    assert derive_display_name("team_sync-2024-03-01.mp3") == "team sync 2024 03 01"
+    assert derive_display_name("Weekly  Standup.wav") == "Weekly Standup"
+    assert derive_display_name("___.mp3") == ""
+    assert derive_display_name("") == ""
+
+
+def test_storage_path_cannot_escape_root(tmp_path):
+    with pytest.raises(ValueError):
+        build_storage_path(
+            str(tmp_path),
+            "../{name}",
+            upload_id=1,
+            profile_id=None,
+            filename="a.wav",
+        )
+
+
+def test_sniff_audio_type():
+    assert sniff_audio_type(b"RIFF\x00\x00\x00\x00WAVE") == "audio/wav"
+    assert sniff_audio_type(b"ID3\x04rest-of-tag") == "audio/mpeg"
+    assert sniff_audio_type(b"fLaC\x00\x00\x00\x22") == "audio/flac"
+    assert sniff_audio_type(b"plain text content") is None
+    assert sniff_audio_type(b"") is None
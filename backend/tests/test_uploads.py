@@ -0,0 +1,174 @@
+from __future__ import annotations
+
+import struct
+
+from app.models import Job, Transcript, TranscriptSegment, Upload
+
+
+def _wav_bytes(payload_size: int = 1024) -> bytes:
+    # Minimal RIFF/WAVE header followed by zeroed payload; enough to pass
+    # magic-byte sniffing without needing a real recording.
+    return b"RIFF" + struct.pack("<I", 36 + payload_size) + b"WAVE" + b"\x00" * payload_size
+
+
+def _make_upload(monkeypatch, client, **form):
+    from app.routes import uploads as uploads_route
+
+    # create_upload refuses to enqueue doomed jobs when ffmpeg/the model is
+    # missing; availability isn't what these tests cover.
+    monkeypatch.setattr(uploads_route, "_pipeline_unavailable_reason", lambda: None)
+    return client.post(
+        "/api/uploads",
+        files={"file": ("team_sync-2024.wav", _wav_bytes(), "audio/wav")},
+        data=form,
+    )
+
+
+def test_create_upload_end_to_end(monkeypatch, client, broker_stub, db_session):
+    r = _make_upload(monkeypatch, client)
+    assert r.status_code == 200
+    body = r.json()
+    assert body["upload_id"] > 0
+    assert body["job_id"] is not None
+
+    # The transcription job goes onto the queue with its priority attached.
+    sends = [c for c in broker_stub if c[0] == "send_task"]
+    assert sends, "expected a process_job enqueue"
+    assert sends[-1][2].get("priority") is not None
+
+    u = db_session.query(Upload).filter(Upload.id == body["upload_id"]).first()
+    assert u is not None
+    # Filename-derived title cleanup (no explicit display_name given).
+    assert u.display_name == "team sync 2024"
+
+
+def test_delete_upload_cancels_active_job(monkeypatch, client, broker_stub, db_session):
+    r = _make_upload(monkeypatch, client)
+    upload_id = r.json()["upload_id"]
+    job_id = r.json()["job_id"]
+
+    r = client.delete(f"/api/uploads/{upload_id}")
+    assert r.status_code == 200
+
+    # In-flight work is revoked and nothing active survives the delete.
+    assert any(c[0] == "revoke" for c in broker_stub)
+    assert db_session.query(Upload).filter(Upload.id == upload_id).first() is None
+    active = (
+        db_session.query(Job)
+        .filter(Job.id == job_id, Job.status.in_(["queued", "processing"]))
+        .count()
+    )
+    assert active == 0
+
+
+def test_missing_upload_returns_404(client):
+    r = client.get("/api/uploads/999999")
+    assert r.status_code == 404
+    assert r.json()["detail"]
+
+
+def test_list_uploads_q_matches_transcript_text(monkeypatch, client, db_session):
+    upload_id = _make_upload(monkeypatch, client).json()["upload_id"]
+    db_session.add(Transcript(upload_id=upload_id, text="quarterly budget review", status="complete"))
+    db_session.commit()
+
+    # "budget" appears only in the transcript, not in any metadata field, so
+    # this match has to come through the FTS index.
+    r = client.get("/api/uploads", params={"q": "budget"})
+    assert r.status_code == 200
+    assert upload_id in [u["id"] for u in r.json()]
+
+    r = client.get("/api/uploads", params={"q": "no-such-phrase-anywhere"})
+    assert upload_id not in [u["id"] for u in r.json()]
+
+
+def test_segments_404_without_transcript(monkeypatch, client):
+    upload_id = _make_upload(monkeypatch, client).json()["upload_id"]
+    r = client.get(f"/api/uploads/{upload_id}/segments")
+    assert r.status_code == 404
+
+
+def test_segments_empty_transcript_is_200(monkeypatch, client, db_session):
+    upload_id = _make_upload(monkeypatch, client).json()["upload_id"]
+    db_session.add(Transcript(upload_id=upload_id, text="", status="complete"))
+    db_session.commit()
+
+    # Zero segments is a legitimate empty list, not a 404.
+    r = client.get(f"/api/uploads/{upload_id}/segments")
+    assert r.status_code == 200
+    assert r.json() == []
+
+
+def test_segments_rejects_bad_min_confidence(monkeypatch, client, db_session):
+    upload_id = _make_upload(monkeypatch, client).json()["upload_id"]
+    db_session.add(Transcript(upload_id=upload_id, text="hi", status="complete"))
+    db_session.add(
+        TranscriptSegment(upload_id=upload_id, start_time=0.0, end_time=1.0, text="hi", kind="speech")
+    )
+    db_session.commit()
+
+    r = client.get(f"/api/uploads/{upload_id}/segments", params={"min_confidence": 1.5})
+    assert r.status_code == 400
+
+    r = client.get(f"/api/uploads/{upload_id}/segments")
+    assert r.status_code == 200
+    assert [s["text"] for s in r.json()] == ["hi"]
+
+
+def _enable_detail_cache(monkeypatch, ttl: float):
+    from app.config import settings
+    from app.routes import uploads as uploads_route
+
+    monkeypatch.setattr(settings, "detail_cache_ttl_seconds", ttl)
+    uploads_route._DETAIL_CACHE.clear()
+
+
+def test_detail_cache_serves_stale_until_ttl_expires(monkeypatch, client, db_session):
+    import time
+
+    upload_id = _make_upload(monkeypatch, client).json()["upload_id"]
+    _enable_detail_cache(monkeypatch, 0.2)
+
+    assert client.get(f"/api/uploads/{upload_id}").json()["notes"] is None
+
+    # Worker-side writes don't invalidate; the cached copy is served until
+    # the TTL runs out.
+    db_session.query(Upload).filter(Upload.id == upload_id).update({"notes": "from the worker"})
+    db_session.commit()
+    assert client.get(f"/api/uploads/{upload_id}").json()["notes"] is None
+
+    time.sleep(0.25)
+    assert client.get(f"/api/uploads/{upload_id}").json()["notes"] == "from the worker"
+
+
+def test_detail_cache_invalidated_by_api_mutation(monkeypatch, client):
+    upload_id = _make_upload(monkeypatch, client).json()["upload_id"]
+    _enable_detail_cache(monkeypatch, 60)
+
+    client.get(f"/api/uploads/{upload_id}")  # prime the cache
+    r = client.patch(f"/api/uploads/{upload_id}", json={"notes": "edited"})
+    assert r.status_code == 200
+
+    # API-side mutations invalidate eagerly — no TTL wait.
+    assert client.get(f"/api/uploads/{upload_id}").json()["notes"] == "edited"
+
+
+def test_detail_cache_disabled_by_default(monkeypatch, client, db_session):
+    upload_id = _make_upload(monkeypatch, client).json()["upload_id"]
+
+    client.get(f"/api/uploads/{upload_id}")
+    db_session.query(Upload).filter(Upload.id == upload_id).update({"notes": "fresh"})
+    db_session.commit()
+    # TTL is 0 by default: every read hits the DB.
+    assert client.get(f"/api/uploads/{upload_id}").json()["notes"] == "fresh"
+
+
+def test_sniffing_rejects_non_audio(monkeypatch, client):
+    from app.routes import uploads as uploads_route
+
+    monkeypatch.setattr(uploads_route, "_pipeline_unavailable_reason", lambda: None)
+    r = client.post(
+        "/api/uploads",
+        files={"file": ("notes.wav", b"this is not audio at all", "audio/wav")},
+    )
+    assert r.status_code == 400
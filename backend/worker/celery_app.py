@@ -1,10 +1,17 @@
 from __future__ import annotations
 
+import logging
+import uuid
+
 from celery import Celery
+from celery.signals import worker_ready
 
 from app.config import settings
 
 
+logger = logging.getLogger(__name__)
+
+
 celery_app = Celery(
     "listen",
     broker=settings.rabbitmq_url,
@@ -15,5 +22,93 @@ celery_app.conf.update(
     task_track_started=True,
     worker_prefetch_multiplier=1,
     task_acks_late=True,
+    # RabbitMQ priority queue so short/interactive jobs don't wait behind a
+    # multi-hour file (see Job.priority). The default sits mid-range, leaving
+    # headroom in both directions; prefetch=1 keeps running jobs unaffected.
+    task_queue_max_priority=10,
+    task_default_priority=5,
 )
 
+@worker_ready.connect
+def _warmup(**_kwargs):
+    # Optional model warm-up so the first job doesn't pay the cold-start
+    # cost (see WARMUP_MODEL). Import lazily: pipeline pulls in
+    # faster-whisper, which the beat process doesn't need.
+    if not settings.warmup_model:
+        return
+    from worker.pipeline import warmup_model
+
+    warmup_model()
+
+
+@worker_ready.connect
+def _recover_interrupted_jobs(**_kwargs):
+    # task_acks_late means a worker killed mid-job leaves its message
+    # unacked and the broker redelivers it — that is the primary recovery
+    # path. This sweep only covers messages that are genuinely gone (broker
+    # reset, queue redeclared): jobs stuck in "processing" that no live
+    # worker still owns get re-enqueued, and Job.chunk_state lets
+    # transcription resume from the last committed chunk. Stamping the new
+    # task id before sending lets process_job drop any stale redelivered
+    # copy, so recovery never races a duplicate run.
+    from app.db import SessionLocal
+    from app.models import Job
+
+    db = SessionLocal()
+    try:
+        stuck = db.query(Job).filter(Job.status == "processing").all()
+        if not stuck:
+            return
+
+        # Task ids still owned by live workers (running or prefetched);
+        # re-enqueueing those would start a concurrent duplicate run.
+        try:
+            inspect = celery_app.control.inspect(timeout=2.0)
+            owned: set[str] = set()
+            for reply in (inspect.active() or {}, inspect.reserved() or {}):
+                for worker_tasks in reply.values():
+                    owned.update(t.get("id") for t in worker_tasks)
+        except Exception:
+            logger.warning("Worker inspect failed; skipping interrupted-job recovery this startup")
+            return
+
+        for job in stuck:
+            if job.kind not in ("transcribe", "llm"):
+                # retranscribe_range arguments aren't persisted on the job
+                # row, so it can't be re-enqueued (the admin retry endpoint
+                # skips it for the same reason).
+                continue
+            if job.celery_task_id and job.celery_task_id in owned:
+                continue
+            done = len(job.chunk_state or [])
+            task_id = uuid.uuid4().hex
+            job.status = "queued"
+            job.phase = None
+            job.started_at = None
+            job.finished_at = None
+            job.celery_task_id = task_id
+            db.commit()
+            celery_app.send_task(
+                "worker.tasks.process_job", args=[job.id], priority=job.priority, task_id=task_id
+            )
+            logger.warning(
+                "Re-enqueued interrupted job %s (upload %s, %d chunks already done)",
+                job.id, job.upload_id, done,
+            )
+    finally:
+        db.close()
+
+
+# Periodic maintenance (requires running the worker with -B / a beat process).
+# The tasks themselves no-op unless the matching retention settings are set.
+celery_app.conf.beat_schedule = {
+    "retention-sweep": {
+        "task": "worker.tasks.sweep_retention",
+        "schedule": 3600.0,
+    },
+    "audio-retention-sweep": {
+        "task": "worker.tasks.sweep_audio_retention",
+        "schedule": 3600.0,
+    },
+}
+
@@ -5,7 +5,11 @@ import json
 import logging
 import math
 import os
+import re
+import signal
 import subprocess
+import threading
+import time
 from dataclasses import dataclass
 from typing import Optional
 
@@ -21,15 +25,180 @@ class Segment:
     start: float
     end: float
     text: str
+    kind: str = "speech"
+    # 0..1, from whisper's avg_logprob; None when the backend doesn't report it.
+    confidence: Optional[float] = None
+
+
+# Bracketed non-speech markers whisper emits instead of transcribed text.
+_NON_SPEECH_RE = re.compile(
+    r"^[\[\(](?:music|applause|laughter|noise|silence|blank[_ ]audio|inaudible)[\]\)]$",
+    re.IGNORECASE,
+)
+
+
+def classify_segment_text(text: str) -> str:
+    """Return "non_speech" for whisper's bracketed event markers, else "speech"."""
+    return "non_speech" if _NON_SPEECH_RE.match(text.strip()) else "speech"
+
+
+# C0 controls plus DEL; segment text is single-line, so newlines go too.
+_CONTROL_CHARS_RE = re.compile(r"[\x00-\x1f\x7f]")
+_WHITESPACE_RE = re.compile(r"\s+")
+
+
+def normalize_text(text: str) -> str:
+    """
+    Clean whisper output for storage: drop control characters, collapse
+    whitespace runs (leading spaces, doubled spaces) to single spaces, trim.
+    """
+    text = _CONTROL_CHARS_RE.sub(" ", text or "")
+    return _WHITESPACE_RE.sub(" ", text).strip()
+
+
+def _communicate(p: subprocess.Popen, cmd: list[str]) -> tuple[str | None, str | None]:
+    """Wait for a process under COMMAND_TIMEOUT_SECONDS, SIGKILLing its whole
+    process group on expiry (ffmpeg doesn't always die promptly from a plain
+    terminate). Every external invocation must go through this."""
+    try:
+        return p.communicate(timeout=settings.command_timeout_seconds)
+    except subprocess.TimeoutExpired:
+        try:
+            os.killpg(os.getpgid(p.pid), signal.SIGKILL)
+        except (ProcessLookupError, PermissionError):
+            pass
+        p.wait()
+        raise RuntimeError(f"command timed out after {settings.command_timeout_seconds}s: {' '.join(cmd)}")
 
 
 def _run(cmd: list[str]) -> None:
-    p = subprocess.run(cmd, stdout=subprocess.DEVNULL, stderr=subprocess.PIPE, text=True)
+    # Own process group so the timeout can kill the whole tree.
+    p = subprocess.Popen(
+        cmd,
+        stdout=subprocess.DEVNULL,
+        stderr=subprocess.PIPE,
+        text=True,
+        start_new_session=True,
+    )
+    _, stderr = _communicate(p, cmd)
     if p.returncode != 0:
-        raise RuntimeError(p.stderr.strip() or f"command failed: {' '.join(cmd)}")
+        raise RuntimeError((stderr or "").strip() or f"command failed: {' '.join(cmd)}")
+
+
+def normalize_to_wav(input_path: str, output_path: str, trim: tuple[float, Optional[float]] | None = None) -> None:
+    cmd = [
+        "ffmpeg",
+        "-hide_banner",
+        "-loglevel",
+        "error",
+        "-y",
+    ]
+    if trim:
+        start, end = trim
+        if start > 0:
+            cmd += ["-ss", str(start)]
+        if end is not None:
+            cmd += ["-to", str(end)]
+    cmd += [
+        "-i",
+        input_path,
+        "-ac",
+        "1",
+        "-ar",
+        str(settings.audio_sample_rate),
+        "-vn",
+        output_path,
+    ]
+    _run(cmd)
+
+
+def detect_silence_bounds(input_path: str, noise_db: int = -45, min_silence: float = 1.0) -> tuple[float, Optional[float]]:
+    """
+    Find leading/trailing silence so uploads with dead air can be trimmed
+    before transcription. Returns (lead_seconds, tail_start_or_None); the
+    original file is never modified.
+    """
+    cmd = [
+        "ffmpeg",
+        "-hide_banner",
+        "-i",
+        input_path,
+        "-af",
+        f"silencedetect=noise={noise_db}dB:d={min_silence}",
+        "-f",
+        "null",
+        "-",
+    ]
+    p = subprocess.Popen(
+        cmd,
+        stdout=subprocess.DEVNULL,
+        stderr=subprocess.PIPE,
+        text=True,
+        start_new_session=True,
+    )
+    _, stderr = _communicate(p, cmd)
+    starts: list[float] = []
+    ends: list[float] = []
+    for line in (stderr or "").splitlines():
+        if "silence_start:" in line:
+            try:
+                starts.append(float(line.rsplit("silence_start:", 1)[1].strip()))
+            except ValueError:
+                pass
+        elif "silence_end:" in line:
+            try:
+                ends.append(float(line.rsplit("silence_end:", 1)[1].split("|")[0].strip()))
+            except ValueError:
+                pass
 
+    lead = 0.0
+    if starts and starts[0] <= 0.5 and ends:
+        lead = ends[0]
 
-def normalize_to_wav(input_path: str, output_path: str) -> None:
+    tail: Optional[float] = None
+    # A trailing silence reports a start with no matching end before EOF.
+    if starts and len(starts) > len(ends):
+        tail = starts[-1]
+
+    if tail is not None and tail <= lead:
+        tail = None
+    return lead, tail
+
+
+def probe_duration(input_path: str) -> Optional[float]:
+    cmd = [
+        "ffprobe",
+        "-v",
+        "error",
+        "-show_entries",
+        "format=duration",
+        "-of",
+        "json",
+        input_path,
+    ]
+    p = subprocess.Popen(
+        cmd,
+        stdout=subprocess.PIPE,
+        stderr=subprocess.DEVNULL,
+        text=True,
+        start_new_session=True,
+    )
+    try:
+        stdout, _ = _communicate(p, cmd)
+    except RuntimeError:
+        # Duration is optional metadata; a hung/killed ffprobe shouldn't
+        # fail the caller, just yield "unknown".
+        return None
+    if p.returncode != 0:
+        return None
+    try:
+        return float(json.loads(stdout)["format"]["duration"])
+    except (KeyError, TypeError, ValueError):
+        return None
+
+
+def extract_range_wav(input_path: str, output_path: str, start: float, end: float) -> None:
+    """Extract [start, end) seconds of audio as a normalized mono WAV."""
     _run(
         [
             "ffmpeg",
@@ -37,12 +206,16 @@ def normalize_to_wav(input_path: str, output_path: str) -> None:
             "-loglevel",
             "error",
             "-y",
+            "-ss",
+            str(start),
+            "-to",
+            str(end),
             "-i",
             input_path,
             "-ac",
             "1",
             "-ar",
-            "16000",
+            str(settings.audio_sample_rate),
             "-vn",
             output_path,
         ]
@@ -71,11 +244,46 @@ def chunk_wav(input_wav: str, chunk_dir: str, chunk_seconds: int) -> list[str]:
         ]
     )
     files = sorted([os.path.join(chunk_dir, f) for f in os.listdir(chunk_dir) if f.startswith("chunk-") and f.endswith(".wav")])
+    if settings.max_chunks and len(files) > settings.max_chunks:
+        raise RuntimeError(
+            f"chunking produced {len(files)} chunks, exceeding MAX_CHUNKS={settings.max_chunks}; "
+            "check the input file or raise the limit"
+        )
     return files
 
 
+# Common words we never want surfaced as transcript "topics".
+_STOPWORDS = frozenset(
+    """a about above after again all am an and any are as at be because been before being below between
+    both but by can did do does doing down during each few for from further had has have having he her
+    here hers him his how i if in into is it its just like me more most my no nor not now of off on once
+    only or other our out over own re s so some such t than that the their them then there these they
+    this those through to too under until up very was we were what when where which while who whom why
+    will with you your yeah yes okay ok right know going gonna got get really think thing things want
+    well said say says one two go um uh""".split()
+)
+
+
+def extract_keywords(text: str, max_keywords: int = 10) -> list[str]:
+    """
+    Cheap frequency-based topic extraction; good enough for filtering without
+    paying for an LLM call.
+    """
+    counts: dict[str, int] = {}
+    for raw in text.lower().split():
+        word = raw.strip(".,;:!?\"'()[]{}-")
+        if len(word) < 4 or word in _STOPWORDS or not word.isalpha():
+            continue
+        counts[word] = counts.get(word, 0) + 1
+    ranked = sorted(counts.items(), key=lambda kv: (-kv[1], kv[0]))
+    return [w for w, n in ranked[:max_keywords] if n > 1]
+
+
 _MODEL: WhisperModel | None = None
 _MODEL_DEVICE: str | None = None
+# Serializes the cold-start load so concurrent tasks don't each trigger a
+# slow read of the model (painful when it lives on a network mount).
+_MODEL_LOCK = threading.Lock()
 
 
 def _cuda_libs_available() -> bool:
@@ -125,25 +333,54 @@ def get_model() -> WhisperModel:
     if _MODEL is not None:
         return _MODEL
 
-    device = _pick_device()
-    try:
-        _MODEL = _build_model(device)
-        _MODEL_DEVICE = device
-        return _MODEL
-    except Exception as e:
-        logger.warning("Failed to init whisper model on %s: %s. Falling back to CPU.", device, e)
-        _MODEL = _build_model("cpu")
-        _MODEL_DEVICE = "cpu"
+    with _MODEL_LOCK:
+        if _MODEL is not None:
+            return _MODEL
+        device = _pick_device()
+        started = time.monotonic()
+        try:
+            _MODEL = _build_model(device)
+            _MODEL_DEVICE = device
+        except Exception as e:
+            logger.warning("Failed to init whisper model on %s: %s. Falling back to CPU.", device, e)
+            _MODEL = _build_model("cpu")
+            _MODEL_DEVICE = "cpu"
+        logger.info(
+            "Loaded whisper model %s on %s in %.1fs",
+            settings.whisper_model, _MODEL_DEVICE, time.monotonic() - started,
+        )
         return _MODEL
 
 
+def warmup_model() -> None:
+    """
+    Optional startup warm-up (WARMUP_MODEL). Loads the model once before any
+    job runs, so the first transcription doesn't eat the cold-start cost —
+    which can be minutes when the model sits on slow or remote storage.
+    """
+    model = settings.whisper_model or ""
+    if (os.sep in model or model.startswith(".")) and os.path.exists(model):
+        try:
+            size = sum(
+                os.path.getsize(os.path.join(dirpath, f))
+                for dirpath, _, files in os.walk(model)
+                for f in files
+            ) if os.path.isdir(model) else os.path.getsize(model)
+            logger.info("Warming up whisper model at %s (%.1f MB)", model, size / 1e6)
+        except OSError:
+            pass
+    get_model()
+
+
 def transcribe_chunk(
     chunk_path: str,
+    language: Optional[str] = None,
 ) -> tuple[str, list[Segment], Optional[str]]:
+    """language pins whisper to a known language; None auto-detects."""
     global _MODEL, _MODEL_DEVICE
     model = get_model()
     try:
-        segments, info = model.transcribe(chunk_path, vad_filter=True)
+        segments, info = model.transcribe(chunk_path, vad_filter=True, language=language)
     except RuntimeError as e:
         # Some environments let WhisperModel(device="cuda") initialize, but fail later when CUDA libs are missing.
         msg = str(e).lower()
@@ -151,22 +388,50 @@ def transcribe_chunk(
             logger.warning("CUDA runtime error (%s). Retrying transcription on CPU.", e)
             _MODEL = _build_model("cpu")
             _MODEL_DEVICE = "cpu"
-            segments, info = _MODEL.transcribe(chunk_path, vad_filter=True)
+            segments, info = _MODEL.transcribe(chunk_path, vad_filter=True, language=language)
         else:
             raise
     language: Optional[str] = getattr(info, "language", None) or None
 
+    text, out_segments = _collect_segments(segments)
+    if not out_segments:
+        # The VAD occasionally classifies an entire quiet/low-bitrate chunk
+        # as non-speech and yields nothing. Retry once without it before
+        # declaring the chunk empty; the normal VAD path stays primary.
+        logger.warning("No segments from %s with VAD; retrying without vad_filter", chunk_path)
+        segments, info = get_model().transcribe(chunk_path, vad_filter=False, language=language)
+        language = getattr(info, "language", None) or language
+        text, out_segments = _collect_segments(segments)
+
+    return text, out_segments, language
+
+
+def _collect_segments(segments) -> tuple[str, list[Segment]]:
+    """Drain a faster-whisper segment iterator into (flat text, segments)."""
     out_segments: list[Segment] = []
     texts: list[str] = []
     for seg in segments:
+        seg_text = normalize_text(seg.text or "")
+        if not seg_text:
+            continue
+        # avg_logprob is a per-segment mean log-probability; exp() maps it
+        # into (0, 1], clipped defensively against backend quirks.
+        avg_logprob = getattr(seg, "avg_logprob", None)
+        confidence = None
+        if avg_logprob is not None:
+            confidence = max(0.0, min(1.0, math.exp(float(avg_logprob))))
         s = Segment(
             start=float(seg.start),
             end=float(seg.end),
-            text=(seg.text or "").strip(),
+            text=seg_text,
+            kind=classify_segment_text(seg_text),
+            confidence=confidence,
         )
-        if s.text:
-            out_segments.append(s)
+        out_segments.append(s)
+        # Non-speech markers are kept as segments but stay out of the flat
+        # transcript text.
+        if s.kind == "speech":
             texts.append(s.text)
 
-    return " ".join(texts).strip(), out_segments, language
+    return " ".join(texts).strip(), out_segments
 
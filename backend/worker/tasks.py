@@ -1,19 +1,94 @@
 from __future__ import annotations
 
 import json
+import logging
 import os
 import re
+import shutil
 import traceback
+from collections import Counter
 from datetime import datetime
 
+from sqlalchemy import insert
 from sqlalchemy.orm import Session
 
 from app.config import settings
 from app.db import SessionLocal
 from app.models import Job, Prompt, Transcript, TranscriptSegment, Upload
 from app.services.openwebui import OpenWebUIClient
+from app.services.runtime_settings import queue_paused
+from app.services.transcripts import record_transcript_version
+from app.services.webhook import notify_job_finished
 from worker.celery_app import celery_app
-from worker.pipeline import chunk_wav, normalize_to_wav, transcribe_chunk
+from worker.pipeline import (
+    chunk_wav,
+    detect_silence_bounds,
+    extract_keywords,
+    extract_range_wav,
+    normalize_to_wav,
+    probe_duration,
+    transcribe_chunk,
+)
+
+
+logger = logging.getLogger(__name__)
+
+
+# Rows per multi-row INSERT. 5 params/row keeps us under SQLite's default
+# 999-variable limit while avoiding thousands of round trips on long
+# recordings.
+_SEGMENT_INSERT_BATCH = 150
+
+# Chunks sampled before the "majority" language strategy locks in a
+# language (see LANGUAGE_DETECT_STRATEGY).
+_LANGUAGE_VOTE_CHUNKS = 3
+
+
+def _insert_segments(
+    db: Session, upload_id: int, rows: list[tuple[float, float, str, str, float | None]]
+) -> None:
+    """Bulk-insert transcript segments in multi-row batches (no commit)."""
+    for i in range(0, len(rows), _SEGMENT_INSERT_BATCH):
+        batch = rows[i : i + _SEGMENT_INSERT_BATCH]
+        db.execute(
+            insert(TranscriptSegment),
+            [
+                {"upload_id": upload_id, "start_time": s, "end_time": e, "text": t, "kind": k, "confidence": c}
+                for s, e, t, k, c in batch
+            ],
+        )
+
+
+def _cleanup_workdir(chunk_dir: str, norm_wav: str, chunks: list[str], chunk_seconds: int) -> None:
+    if settings.keep_workdir:
+        manifest = [
+            {"chunk": idx, "path": p, "offset_seconds": (idx - 1) * chunk_seconds}
+            for idx, p in enumerate(chunks, start=1)
+        ]
+        try:
+            with open(os.path.join(chunk_dir, "manifest.json"), "w", encoding="utf-8") as f:
+                json.dump(manifest, f, indent=2)
+        except OSError:
+            pass
+        logger.info("KEEP_WORKDIR set; retaining chunks in %s", chunk_dir)
+        return
+    shutil.rmtree(chunk_dir, ignore_errors=True)
+    try:
+        os.remove(norm_wav)
+    except OSError:
+        pass
+
+
+def _cap_transcript_text(text: str, upload_id: int) -> str:
+    """Enforce MAX_TRANSCRIPT_CHARS on the flat text; segments keep it all."""
+    cap = settings.max_transcript_chars
+    if cap <= 0 or len(text) <= cap:
+        return text
+    logger.warning(
+        "Transcript for upload %s is %d chars; truncating to %d (MAX_TRANSCRIPT_CHARS)",
+        upload_id, len(text), cap,
+    )
+    return text[:cap].rstrip() + " […truncated; full text available via segments]"
 
 
 def _update_job(db: Session, job: Job, **kwargs) -> None:
@@ -22,6 +97,27 @@ def _update_job(db: Session, job: Job, **kwargs) -> None:
     db.commit()
 
 
+def _notify_finished(db: Session, job: Job) -> None:
+    """Fire the completion webhook for a job that just reached a terminal state."""
+    transcript_id = (
+        db.query(Transcript.id).filter(Transcript.upload_id == job.upload_id).scalar()
+    )
+    notify_job_finished(
+        upload_id=job.upload_id,
+        job_id=job.id,
+        status=job.status,
+        transcript_id=transcript_id,
+        error=job.error,
+    )
+
+
+def _job_canceled(db: Session, job_id: int) -> bool:
+    # The job row (or its upload) can disappear or be canceled mid-run when
+    # the user deletes the conversation; check fresh state from the DB.
+    status = db.query(Job.status).filter(Job.id == job_id).scalar()
+    return status is None or status == "canceled"
+
+
 def _get_prompt(db: Session, kind: str, preferred_id: int | None) -> str:
     if preferred_id is not None:
         p = db.query(Prompt).filter(Prompt.id == preferred_id, Prompt.kind == kind).first()
@@ -56,55 +152,189 @@ def process_upload(job_id: int) -> None:
         job.progress = 5
         db.commit()
 
+        # Create a placeholder transcript up front so the UI has something to
+        # render immediately; it's filled in (not re-created) at completion.
+        tr = db.query(Transcript).filter(Transcript.upload_id == upload.id).first()
+        if not tr:
+            tr = Transcript(upload_id=upload.id, text="", status="processing")
+            db.add(tr)
+        else:
+            tr.status = "processing"
+        db.commit()
+
         # Prepare paths
         upload_dir = os.path.join(settings.upload_dir, str(upload.id))
         os.makedirs(upload_dir, exist_ok=True)
         norm_wav = os.path.join(upload_dir, "normalized.wav")
         chunk_dir = os.path.join(upload_dir, "chunks")
 
-        normalize_to_wav(upload.stored_path, norm_wav)
-        chunks = chunk_wav(norm_wav, chunk_dir, settings.audio_chunk_seconds)
+        chunk_seconds = job.chunk_seconds or settings.audio_chunk_seconds
+
+        trim = None
+        if job.trim_silence:
+            lead, tail = detect_silence_bounds(upload.stored_path)
+            if lead > 0 or tail is not None:
+                trim = (lead, tail)
+                upload.trim_start_seconds = lead if lead > 0 else None
+                upload.trim_end_seconds = tail
+                db.commit()
+
+        normalize_to_wav(upload.stored_path, norm_wav, trim=trim)
+        if trim:
+            # Playback serves the original file, so keep the original
+            # duration; trim_start/end_seconds record what was cut.
+            upload.duration_seconds = probe_duration(upload.stored_path)
+        else:
+            upload.duration_seconds = probe_duration(norm_wav)
+        db.commit()
+
+        chunks = chunk_wav(norm_wav, chunk_dir, chunk_seconds)
+        if not chunks:
+            # A valid but fully silent input can make ffmpeg's segment muxer
+            # emit nothing. That's not a failure: store an empty transcript
+            # so the user still gets their upload back.
+            tr = db.query(Transcript).filter(Transcript.upload_id == upload.id).first()
+            if tr:
+                tr.text = ""
+                tr.status = "complete"
+            else:
+                db.add(Transcript(upload_id=upload.id, text="", status="complete"))
+            job.status = "completed"
+            job.progress = 100
+            job.phase = None
+            job.total_chunks = 0
+            job.finished_at = datetime.utcnow()
+            db.commit()
+            _notify_finished(db, job)
+            return
+
         job.total_chunks = len(chunks)
         job.current_chunk = 0
         job.progress = 10
         job.phase = "transcribing"
+        # Resume bookkeeping: chunk_state records committed chunks from a
+        # previous attempt of this job. Skip the contiguous prefix (commits
+        # are sequential, so anything after the first gap is untrusted).
+        done = set(job.chunk_state or [])
+        resume_from = 0
+        while (resume_from + 1) in done:
+            resume_from += 1
+        if resume_from:
+            logger.info(
+                "Resuming job %s at chunk %d/%d; earlier chunks kept from the previous attempt",
+                job.id, resume_from + 1, len(chunks),
+            )
+        # Clear segments from the resume point on so the per-chunk inserts
+        # below are the only writers and the segments endpoint can serve
+        # partial results; resumed chunks keep their committed segments.
+        db.query(TranscriptSegment).filter(
+            TranscriptSegment.upload_id == upload.id,
+            TranscriptSegment.start_time >= resume_from * chunk_seconds,
+        ).delete()
+        job.chunk_state = list(range(1, resume_from + 1))
         db.commit()
 
-        # Transcribe chunk-by-chunk with progress updates
-        # faster-whisper runs per chunk inside transcribe_chunks; we update current_chunk around that
+        # Transcribe chunk-by-chunk, committing each chunk's segments as it
+        # completes so long files become readable progressively.
         transcript_text_parts: list[str] = []
-        all_segments: list[tuple[float, float, str]] = []
-        language: str | None = None
+        # A language stored on the job pins whisper; otherwise auto-detect.
+        # "first" records the first chunk's detection; "majority" collects
+        # votes from the first few chunks and then pins whisper to the
+        # winner for the rest of the file (LANGUAGE_DETECT_STRATEGY).
+        language: str | None = job.language
+        pinned_language: str | None = job.language
+        lang_votes: list[str] = []
+        failed_chunks: list[int] = []
 
         for idx, chunk_path in enumerate(chunks, start=1):
+            if idx <= resume_from:
+                continue
+            if _job_canceled(db, job_id):
+                return
             job.current_chunk = idx
             # 10..80% while transcribing
             job.progress = 10 + int(70 * idx / max(1, len(chunks)))
             db.commit()
 
-            text, segs, lang = transcribe_chunk(chunk_path)
-            if lang and not language:
-                language = lang
+            try:
+                text, segs, lang = transcribe_chunk(chunk_path, language=pinned_language)
+            except Exception:
+                if not settings.continue_on_chunk_error:
+                    raise
+                # One retry for transient failures, then skip the chunk and
+                # leave a gap marker so the hole is visible in the transcript.
+                try:
+                    text, segs, lang = transcribe_chunk(chunk_path, language=pinned_language)
+                except Exception:
+                    logger.exception(
+                        "Chunk %d/%d failed twice; skipping (upload %s)", idx, len(chunks), upload.id
+                    )
+                    failed_chunks.append(idx)
+                    offset = (idx - 1) * chunk_seconds
+                    _insert_segments(
+                        db,
+                        upload.id,
+                        [(offset, offset + chunk_seconds, "[TRANSCRIPTION GAP]", "non_speech", None)],
+                    )
+                    db.commit()
+                    continue
+            if lang and not job.language:
+                if settings.language_detect_strategy == "majority":
+                    if pinned_language is None:
+                        lang_votes.append(lang)
+                        if len(lang_votes) >= _LANGUAGE_VOTE_CHUNKS:
+                            pinned_language = Counter(lang_votes).most_common(1)[0][0]
+                            language = pinned_language
+                elif not language:
+                    language = lang
             if text:
                 transcript_text_parts.append(text)
-            for s in segs:
-                offset = (idx - 1) * settings.audio_chunk_seconds
-                all_segments.append((s.start + offset, s.end + offset, s.text))
-
-        transcript_text = " ".join(transcript_text_parts).strip()
+            offset = (idx - 1) * chunk_seconds
+            _insert_segments(
+                db,
+                upload.id,
+                [(s.start + offset, s.end + offset, s.text, s.kind, s.confidence) for s in segs],
+            )
+            # Mark the chunk committed in the same transaction as its
+            # segments so chunk_state never runs ahead of the data.
+            job.chunk_state = [*(job.chunk_state or []), idx]
+            db.commit()
 
-        # Persist transcript + segments
-        db.query(TranscriptSegment).filter(TranscriptSegment.upload_id == upload.id).delete()
-        db.query(Transcript).filter(Transcript.upload_id == upload.id).delete()
-        db.commit()
+        # Short files can end before the majority vote closes; settle it
+        # from whatever votes came in.
+        if language is None and lang_votes:
+            language = Counter(lang_votes).most_common(1)[0][0]
+
+        if resume_from:
+            # Text for resumed chunks only exists as segments; rebuild the
+            # flat transcript from them instead of the in-memory parts.
+            rows = (
+                db.query(TranscriptSegment)
+                .filter(TranscriptSegment.upload_id == upload.id)
+                .order_by(TranscriptSegment.start_time.asc())
+                .all()
+            )
+            flat = " ".join(s.text for s in rows if s.text and s.kind == "speech").strip()
+        else:
+            flat = " ".join(transcript_text_parts).strip()
+        transcript_text = _cap_transcript_text(flat, upload.id)
 
-        tr = Transcript(upload_id=upload.id, text=transcript_text)
-        db.add(tr)
+        if _job_canceled(db, job_id):
+            return
 
-        for start, end, text in all_segments:
-            db.add(TranscriptSegment(upload_id=upload.id, start_time=start, end_time=end, text=text))
+        # Finalize the transcript; segments were already written per chunk.
+        tr = db.query(Transcript).filter(Transcript.upload_id == upload.id).first()
+        if tr:
+            tr.text = transcript_text
+            tr.status = "complete"
+        else:
+            tr = Transcript(upload_id=upload.id, text=transcript_text, status="complete")
+            db.add(tr)
+        record_transcript_version(db, upload.id, transcript_text, source="whisper")
 
         upload.language = language
+        if settings.generate_keywords and transcript_text:
+            upload.keywords = extract_keywords(transcript_text, settings.max_keywords)
         db.commit()
 
         job.progress = 85
@@ -125,29 +355,63 @@ def process_upload(job_id: int) -> None:
                 prompt = _get_prompt(db, "summary", job.prompt_summary_id)
                 summary = _run_async(client.chat_completion(model=model, system_prompt=prompt, user_text=transcript_text))
                 upload.summary = _normalize_markdown(summary)
+                # Keep the bar moving between transcription (85) and done (100)
+                # so the UI never sits on a stale value during LLM calls.
+                job.progress = 92
                 db.commit()
 
             if job.generate_action_items:
+                job.phase = "action_items"
                 prompt = _get_prompt(db, "action_items", job.prompt_action_items_id)
                 raw = _run_async(client.chat_completion(model=model, system_prompt=prompt, user_text=transcript_text))
                 upload.action_items = _best_effort_json(raw)
+                job.progress = 96
                 db.commit()
 
-        job.status = "completed"
+        if failed_chunks:
+            job.status = "completed_with_errors"
+            job.error = "chunks failed after retry: " + ", ".join(str(i) for i in failed_chunks)
+        else:
+            job.status = "completed"
         job.progress = 100
         job.phase = None
         job.finished_at = datetime.utcnow()
         db.commit()
+        _notify_finished(db, job)
+
+        # Optional archival to object storage. Strictly best-effort: the job
+        # is already completed, so an archive failure only gets logged.
+        if settings.archive_on_completion:
+            try:
+                from app.services.archive import archive_enabled, archive_upload
+
+                if archive_enabled():
+                    archive_upload(db, upload)
+            except Exception:
+                logger.exception("Archival failed for upload %s (job stays completed)", upload.id)
+
+        # On success the intermediates are no longer needed (on failure we
+        # leave them in place for inspection).
+        _cleanup_workdir(chunk_dir, norm_wav, chunks, chunk_seconds)
 
     except Exception as e:
         try:
+            db.rollback()
             job = db.query(Job).filter(Job.id == job_id).first()
             if job:
                 job.status = "failed"
                 job.progress = 100
                 job.error = str(e)
                 job.finished_at = datetime.utcnow()
+                # Drop an untouched placeholder transcript so a failed job
+                # doesn't leave an empty "processing" transcript behind.
+                db.query(Transcript).filter(
+                    Transcript.upload_id == job.upload_id,
+                    Transcript.status == "processing",
+                    Transcript.text == "",
+                ).delete()
                 db.commit()
+                _notify_finished(db, job)
         finally:
             traceback.print_exc()
     finally:
@@ -197,32 +461,146 @@ def process_llm(job_id: int) -> None:
         )
         model = job.llm_model or settings.openwebui_default_model
 
+        # Run each requested step independently so one failing LLM call
+        # doesn't throw away the other step's result.
+        step_errors: list[str] = []
+        steps_requested = 0
+
         if job.summarize:
+            steps_requested += 1
             job.phase = "summarizing"
             job.progress = 25 if job.generate_action_items else 50
             db.commit()
-            prompt = _get_prompt(db, "summary", job.prompt_summary_id)
-            summary = _run_async(client.chat_completion(model=model, system_prompt=prompt, user_text=transcript_text))
-            upload.summary = _normalize_markdown(summary)
-            db.commit()
+            try:
+                prompt = _get_prompt(db, "summary", job.prompt_summary_id)
+                summary = _run_async(client.chat_completion(model=model, system_prompt=prompt, user_text=transcript_text))
+                upload.summary = _normalize_markdown(summary)
+                db.commit()
+            except Exception as e:
+                db.rollback()
+                step_errors.append(f"summary: {e}")
 
         if job.generate_action_items:
+            steps_requested += 1
             job.phase = "action_items"
             job.progress = 75
             db.commit()
-            prompt = _get_prompt(db, "action_items", job.prompt_action_items_id)
-            raw = _run_async(client.chat_completion(model=model, system_prompt=prompt, user_text=transcript_text))
-            upload.action_items = _best_effort_json(raw)
-            db.commit()
+            try:
+                prompt = _get_prompt(db, "action_items", job.prompt_action_items_id)
+                raw = _run_async(client.chat_completion(model=model, system_prompt=prompt, user_text=transcript_text))
+                upload.action_items = _best_effort_json(raw)
+                db.commit()
+            except Exception as e:
+                db.rollback()
+                step_errors.append(f"action_items: {e}")
+
+        job.status = "failed" if step_errors and len(step_errors) == steps_requested else "completed"
+        job.error = "; ".join(step_errors) if step_errors else None
+        job.progress = 100
+        job.phase = None
+        job.finished_at = datetime.utcnow()
+        db.commit()
+        _notify_finished(db, job)
+
+    except Exception as e:
+        try:
+            db.rollback()
+            job = db.query(Job).filter(Job.id == job_id).first()
+            if job:
+                job.status = "failed"
+                job.progress = 100
+                job.error = str(e)
+                job.finished_at = datetime.utcnow()
+                db.commit()
+                _notify_finished(db, job)
+        finally:
+            traceback.print_exc()
+    finally:
+        db.close()
+
+
+@celery_app.task(name="worker.tasks.retranscribe_range")
+def retranscribe_range(job_id: int, start: float, end: float) -> None:
+    """
+    Re-run whisper on a single [start, end) slice of the original audio and
+    replace the overlapping segments, leaving the rest of the transcript
+    untouched.
+    """
+    db = SessionLocal()
+    try:
+        job = db.query(Job).filter(Job.id == job_id).first()
+        if not job:
+            return
+        upload = db.query(Upload).filter(Upload.id == job.upload_id).first()
+        if not upload or not upload.stored_path or not os.path.exists(upload.stored_path):
+            _update_job(db, job, status="failed", error="audio file not found", finished_at=datetime.utcnow(), progress=100)
+            return
+
+        job.status = "processing"
+        job.started_at = datetime.utcnow()
+        job.phase = "transcribing"
+        job.progress = 10
+        db.commit()
+
+        upload_dir = os.path.join(settings.upload_dir, str(upload.id))
+        os.makedirs(upload_dir, exist_ok=True)
+        slice_wav = os.path.join(upload_dir, f"range-{int(start)}-{int(end)}.wav")
+        extract_range_wav(upload.stored_path, slice_wav, start, end)
+
+        job.progress = 30
+        db.commit()
+
+        _, segs, _ = transcribe_chunk(slice_wav, language=job.language or upload.language)
+
+        # Replace segments overlapping the range with the re-transcribed ones.
+        db.query(TranscriptSegment).filter(
+            TranscriptSegment.upload_id == upload.id,
+            TranscriptSegment.end_time > start,
+            TranscriptSegment.start_time < end,
+        ).delete()
+        _insert_segments(
+            db,
+            upload.id,
+            [(s.start + start, s.end + start, s.text, s.kind, s.confidence) for s in segs],
+        )
+        db.commit()
+
+        job.progress = 80
+        db.commit()
+
+        # Rebuild the flat transcript text from the (now corrected) segments.
+        rows = (
+            db.query(TranscriptSegment)
+            .filter(TranscriptSegment.upload_id == upload.id)
+            .order_by(TranscriptSegment.start_time.asc())
+            .all()
+        )
+        transcript_text = _cap_transcript_text(
+            " ".join(s.text for s in rows if s.text and s.kind == "speech").strip(), upload.id
+        )
+        tr = db.query(Transcript).filter(Transcript.upload_id == upload.id).first()
+        if tr:
+            tr.text = transcript_text
+            tr.status = "complete"
+        else:
+            db.add(Transcript(upload_id=upload.id, text=transcript_text, status="complete"))
+        record_transcript_version(db, upload.id, transcript_text, source="whisper")
 
         job.status = "completed"
         job.progress = 100
         job.phase = None
         job.finished_at = datetime.utcnow()
         db.commit()
+        _notify_finished(db, job)
+
+        try:
+            os.remove(slice_wav)
+        except OSError:
+            pass
 
     except Exception as e:
         try:
+            db.rollback()
             job = db.query(Job).filter(Job.id == job_id).first()
             if job:
                 job.status = "failed"
@@ -230,12 +608,172 @@ def process_llm(job_id: int) -> None:
                 job.error = str(e)
                 job.finished_at = datetime.utcnow()
                 db.commit()
+                _notify_finished(db, job)
         finally:
             traceback.print_exc()
     finally:
         db.close()
 
 
+# Registry of job kinds -> handlers. Endpoints record the kind on the job
+# row and enqueue the generic dispatcher; new job types only need an entry
+# here plus a kind at creation time.
+JOB_HANDLERS = {
+    "transcribe": process_upload,
+    "llm": process_llm,
+    "retranscribe_range": retranscribe_range,
+}
+
+
+@celery_app.task(name="worker.tasks.process_job", bind=True, max_retries=None)
+def process_job(self, job_id: int, **kwargs) -> None:
+    db = SessionLocal()
+    try:
+        # While the queue is paused (admin endpoint), requeue with a delay
+        # instead of running; jobs accumulate and proceed on resume.
+        if queue_paused(db):
+            raise self.retry(countdown=15)
+        row = db.query(Job.kind, Job.celery_task_id).filter(Job.id == job_id).first()
+    finally:
+        db.close()
+    if row is None:
+        return
+    kind, expected_task_id = row
+
+    # A redelivered message for a job that has since been re-enqueued under
+    # a new task id (startup recovery, admin retry) is stale: running it
+    # would race the fresh run. Drop it. Fresh jobs whose task id hasn't
+    # been stamped yet (None) always run.
+    if expected_task_id and self.request.id and expected_task_id != self.request.id:
+        logger.info(
+            "Dropping stale delivery %s for job %s (current task %s)",
+            self.request.id, job_id, expected_task_id,
+        )
+        return
+
+    handler = JOB_HANDLERS.get(kind)
+    if handler is None:
+        db = SessionLocal()
+        try:
+            job = db.query(Job).filter(Job.id == job_id).first()
+            if job:
+                _update_job(db, job, status="failed", error=f"unknown job kind: {kind}", finished_at=datetime.utcnow(), progress=100)
+        finally:
+            db.close()
+        return
+
+    handler.run(job_id, **kwargs)
+
+
+@celery_app.task(name="worker.tasks.sweep_retention")
+def sweep_retention() -> None:
+    """
+    Periodic retention sweep (celery beat). Deletes uploads older than
+    RETENTION_DAYS along with their files. Skips favorites and anything
+    with a queued/processing job so it's safe alongside active work.
+    """
+    if settings.retention_days <= 0:
+        return
+
+    from datetime import timedelta
+
+    from app.services.storage import delete_tree
+
+    cutoff = datetime.utcnow() - timedelta(days=settings.retention_days)
+    db = SessionLocal()
+    try:
+        candidates = (
+            db.query(Upload)
+            .filter(Upload.created_at < cutoff, Upload.is_favorite == False)
+            .all()
+        )
+        removed = 0
+        for u in candidates:
+            active = (
+                db.query(Job)
+                .filter(Job.upload_id == u.id, Job.status.in_(["queued", "processing"]))
+                .count()
+            )
+            if active:
+                continue
+            # Capture everything needed after the delete: commit expires the
+            # instance, so attribute access on it would raise.
+            upload_id, display_name, stored_path = u.id, u.display_name, u.stored_path
+            upload_dir = os.path.join(settings.upload_dir, str(upload_id))
+            db.delete(u)
+            db.commit()
+            if stored_path and os.path.exists(stored_path):
+                try:
+                    os.remove(stored_path)
+                except OSError:
+                    pass
+            delete_tree(upload_dir)
+            removed += 1
+            logger.info("Retention sweep removed upload %s (%s)", upload_id, display_name)
+        if removed:
+            logger.info("Retention sweep removed %d upload(s) older than %d days", removed, settings.retention_days)
+    finally:
+        db.close()
+
+
+@celery_app.task(name="worker.tasks.sweep_audio_retention")
+def sweep_audio_retention() -> None:
+    """
+    Audio-only retention sweep (celery beat): removes just the audio files
+    for uploads older than AUDIO_RETENTION_DAYS, keeping transcripts and
+    metadata. Same exemptions as sweep_retention (favorites, active jobs).
+    """
+    if settings.audio_retention_days <= 0:
+        return
+
+    from datetime import timedelta
+
+    from app.services.storage import delete_tree
+
+    cutoff = datetime.utcnow() - timedelta(days=settings.audio_retention_days)
+    db = SessionLocal()
+    try:
+        candidates = (
+            db.query(Upload)
+            .filter(
+                Upload.created_at < cutoff,
+                Upload.is_favorite == False,
+                Upload.audio_deleted_at == None,
+                Upload.stored_path != "",
+            )
+            .all()
+        )
+        removed = 0
+        for u in candidates:
+            active = (
+                db.query(Job)
+                .filter(Job.upload_id == u.id, Job.status.in_(["queued", "processing"]))
+                .count()
+            )
+            if active:
+                continue
+            # The per-upload dir holds only audio-derived artifacts (the file,
+            # waveform/playback caches, leftover workdirs); the transcript
+            # lives in the DB.
+            delete_tree(os.path.join(settings.upload_dir, str(u.id)))
+            if u.stored_path and os.path.exists(u.stored_path):
+                try:
+                    os.remove(u.stored_path)
+                except OSError:
+                    pass
+            u.stored_path = ""
+            u.audio_deleted_at = datetime.utcnow()
+            db.commit()
+            removed += 1
+        if removed:
+            logger.info(
+                "Audio retention sweep removed audio for %d upload(s) older than %d days",
+                removed, settings.audio_retention_days,
+            )
+    finally:
+        db.close()
+
+
 def _best_effort_json(raw: str):
     raw = (raw or "").strip()
     if not raw: